@@ -80,6 +80,7 @@ func newCmd() *cmd {
 
 	app.Flags = []cli.Flag{
 		debugFlags,
+		commands.OutputFlag(),
 	}
 	app.Commands = []*cli.Command{
 		commands.Info,
@@ -95,6 +96,21 @@ func newCmd() *cmd {
 		commands.Add,
 		commands.Activate,
 		commands.Env,
+		commands.Cache,
+		commands.Plugin,
+		commands.Exec,
+		commands.Reshim,
+		commands.Shim,
+		commands.Which,
+		commands.Where,
+		commands.Outdated,
+		commands.Upgrade,
+		commands.Prune,
+		commands.Link,
+		commands.Alias,
+	}
+	for _, command := range app.Commands {
+		commands.WithOutputFlag(command)
 	}
 
 	return &cmd{app: app, version: version}
@@ -0,0 +1,183 @@
+/*
+ *    Copyright 2024 Han Li and contributors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/urfave/cli/v2"
+	"github.com/version-fox/vfox/internal"
+)
+
+var Alias = &cli.Command{
+	Name:  "alias",
+	Usage: "manage the version aliases of sdks",
+	Subcommands: []*cli.Command{
+		{
+			Name:      "set",
+			Usage:     "point an alias to a version",
+			UsageText: "vfox alias set <sdk-name> <alias> <version>",
+			Action:    aliasSetCmd,
+		},
+		{
+			Name:      "unset",
+			Usage:     "remove an alias",
+			UsageText: "vfox alias unset <sdk-name> <alias>",
+			Action:    aliasUnsetCmd,
+		},
+		{
+			Name:      "list",
+			Usage:     "list the aliases, including the aliases provided by the plugin if a sdk is specified",
+			UsageText: "vfox alias list [<sdk-name>]",
+			Action:    aliasListCmd,
+		},
+	},
+}
+
+func aliasSetCmd(ctx *cli.Context) error {
+	args := ctx.Args()
+	if args.Len() != 3 {
+		return cli.Exit("invalid arguments, usage: "+ctx.Command.UsageText, 1)
+	}
+	manager := internal.NewSdkManager()
+	defer manager.Close()
+	source, aliases, err := lookupAliases(manager, args.Get(0))
+	if err != nil {
+		return err
+	}
+	alias, version := args.Get(1), internal.Version(args.Get(2))
+	if err = aliases.Set(alias, version); err != nil {
+		return cli.Exit(err.Error(), 1)
+	}
+	if err = aliases.Save(); err != nil {
+		return err
+	}
+	pterm.Printf("Alias %s now points to %s.\n", pterm.LightBlue(alias), pterm.LightGreen(strings.ToLower(source.Plugin.SdkName)+"@"+string(version)))
+	return nil
+}
+
+func aliasUnsetCmd(ctx *cli.Context) error {
+	args := ctx.Args()
+	if args.Len() != 2 {
+		return cli.Exit("invalid arguments, usage: "+ctx.Command.UsageText, 1)
+	}
+	manager := internal.NewSdkManager()
+	defer manager.Close()
+	_, aliases, err := lookupAliases(manager, args.Get(0))
+	if err != nil {
+		return err
+	}
+	alias := args.Get(1)
+	if !aliases.Remove(alias) {
+		return cli.Exit(fmt.Sprintf("alias %s not found", alias), 1)
+	}
+	if err = aliases.Save(); err != nil {
+		return err
+	}
+	pterm.Printf("Alias %s removed.\n", pterm.LightBlue(alias))
+	return nil
+}
+
+// aliasItem is the structured output of `vfox alias list`, the source is either user or plugin.
+type aliasItem struct {
+	Sdk     string `json:"sdk" yaml:"sdk"`
+	Alias   string `json:"alias" yaml:"alias"`
+	Version string `json:"version" yaml:"version"`
+	Source  string `json:"source" yaml:"source"`
+}
+
+func aliasListCmd(ctx *cli.Context) error {
+	manager := internal.NewSdkManager()
+	defer manager.Close()
+	name := ctx.Args().First()
+	var names []string
+	if name != "" {
+		names = append(names, strings.ToLower(name))
+	} else {
+		dir, err := os.ReadDir(manager.PathMeta.PluginPath)
+		if err != nil {
+			return err
+		}
+		for _, d := range dir {
+			if d.IsDir() {
+				names = append(names, d.Name())
+			}
+		}
+		sort.Strings(names)
+	}
+	items := []aliasItem{}
+	for _, sdkName := range names {
+		source, aliases, err := lookupAliases(manager, sdkName)
+		if err != nil {
+			return err
+		}
+		for _, alias := range aliases.Names() {
+			items = append(items, aliasItem{Sdk: sdkName, Alias: alias, Version: aliases.Versions[alias], Source: "user"})
+		}
+		// The plugin aliases need to fetch the available versions, only for the specified sdk.
+		if name == "" {
+			continue
+		}
+		pluginAliases, err := source.PluginAliases()
+		if err != nil {
+			pterm.Printf("Failed to get the aliases of the plugin, err: %s\n", err)
+			continue
+		}
+		var sorted []string
+		for alias := range pluginAliases {
+			sorted = append(sorted, alias)
+		}
+		sort.Strings(sorted)
+		for _, alias := range sorted {
+			items = append(items, aliasItem{Sdk: sdkName, Alias: alias, Version: string(pluginAliases[alias]), Source: "plugin"})
+		}
+	}
+	if isStructured() {
+		return printStructured(items)
+	}
+	if len(items) == 0 {
+		pterm.Println("No alias found.")
+		return nil
+	}
+	data := pterm.TableData{
+		{"SDK", "ALIAS", "VERSION", "SOURCE"},
+	}
+	for _, item := range items {
+		data = append(data, []string{item.Sdk, item.Alias, item.Version, item.Source})
+	}
+	return pterm.DefaultTable.
+		WithHasHeader().
+		WithSeparator("\t ").
+		WithData(data).Render()
+}
+
+func lookupAliases(manager *internal.Manager, name string) (*internal.Sdk, *internal.Aliases, error) {
+	name = strings.ToLower(name)
+	source, err := manager.LookupSdk(name)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s not supported, error: %w", name, err)
+	}
+	aliases, err := source.Aliases()
+	if err != nil {
+		return nil, nil, err
+	}
+	return source, aliases, nil
+}
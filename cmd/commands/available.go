@@ -28,6 +28,15 @@ var Available = &cli.Command{
 	Action: availableCmd,
 }
 
+// availableItem is the structured output of `vfox available`.
+type availableItem struct {
+	Name        string `json:"name" yaml:"name"`
+	Category    string `json:"category" yaml:"category"`
+	Version     string `json:"version" yaml:"version"`
+	Author      string `json:"author" yaml:"author"`
+	Description string `json:"description" yaml:"description"`
+}
+
 func availableCmd(ctx *cli.Context) error {
 	manager := internal.NewSdkManagerWithSource()
 	defer manager.Close()
@@ -36,6 +45,24 @@ func availableCmd(ctx *cli.Context) error {
 	if err != nil {
 		return err
 	}
+	if isStructured() {
+		items := []availableItem{}
+		for _, category := range categories {
+			if len(categoryName) > 0 && categoryName != category.Name {
+				continue
+			}
+			for _, p := range category.Plugins {
+				items = append(items, availableItem{
+					Name:        category.Name + "/" + p.Filename,
+					Category:    category.Name,
+					Version:     p.Version,
+					Author:      p.Author,
+					Description: p.Desc,
+				})
+			}
+		}
+		return printStructured(items)
+	}
 	data := pterm.TableData{
 		{"NAME", "VERSION", "AUTHOR", "DESCRIPTION"},
 	}
@@ -0,0 +1,149 @@
+/*
+ *    Copyright 2024 Han Li and contributors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/urfave/cli/v2"
+	"github.com/version-fox/vfox/internal"
+	"github.com/version-fox/vfox/internal/util"
+)
+
+var Cache = &cli.Command{
+	Name:  "cache",
+	Usage: "manage the download cache",
+	Subcommands: []*cli.Command{
+		{
+			Name:   "list",
+			Usage:  "list the cached files",
+			Action: cacheListCmd,
+		},
+		{
+			Name:   "clean",
+			Usage:  "remove all cached files",
+			Action: cacheCleanCmd,
+		},
+		{
+			Name:  "prune",
+			Usage: "remove the cached files which have not been used for a while",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "older-than",
+					Usage: "remove the files not used for the duration, such as 30d, 2w, 12h",
+					Value: "30d",
+				},
+			},
+			Action: cachePruneCmd,
+		},
+	},
+}
+
+// cacheItem is the structured output of `vfox cache list`, the status is either completed or partial.
+type cacheItem struct {
+	File     string `json:"file" yaml:"file"`
+	Size     int64  `json:"size" yaml:"size"`
+	Status   string `json:"status" yaml:"status"`
+	LastUsed int64  `json:"lastUsed" yaml:"lastUsed"`
+	Url      string `json:"url" yaml:"url"`
+}
+
+func cacheListCmd(ctx *cli.Context) error {
+	manager := internal.NewSdkManagerWithSource()
+	defer manager.Close()
+	entries, err := manager.DownloadCache.List()
+	if err != nil {
+		return err
+	}
+	if isStructured() {
+		items := make([]cacheItem, 0, len(entries))
+		for _, entry := range entries {
+			status := "completed"
+			if !entry.IsCompleted() {
+				status = "partial"
+			}
+			items = append(items, cacheItem{
+				File:     entry.Filename,
+				Size:     entry.Size,
+				Status:   status,
+				LastUsed: entry.LastUsed,
+				Url:      entry.Url,
+			})
+		}
+		return printStructured(items)
+	}
+	if len(entries) == 0 {
+		pterm.Println("The download cache is empty.")
+		return nil
+	}
+	data := pterm.TableData{
+		{"FILE", "SIZE", "STATUS", "LAST USED", "URL"},
+	}
+	var total int64
+	for _, entry := range entries {
+		status := "completed"
+		if !entry.IsCompleted() {
+			status = "partial"
+		}
+		total += entry.Size
+		data = append(data, []string{
+			entry.Filename,
+			util.FormatSize(entry.Size),
+			status,
+			time.Unix(entry.LastUsed, 0).Format(time.DateTime),
+			entry.Url,
+		})
+	}
+	_ = pterm.DefaultTable.
+		WithHasHeader().
+		WithSeparator("\t ").
+		WithData(data).Render()
+	pterm.Printf("Total: %s\n", pterm.LightGreen(util.FormatSize(total)))
+	return nil
+}
+
+func cacheCleanCmd(ctx *cli.Context) error {
+	manager := internal.NewSdkManagerWithSource()
+	defer manager.Close()
+	if err := manager.DownloadCache.Clean(); err != nil {
+		return fmt.Errorf("clean cache failed, err: %w", err)
+	}
+	pterm.Println("Clean the download cache successfully!")
+	return nil
+}
+
+func cachePruneCmd(ctx *cli.Context) error {
+	olderThan, err := util.ParseDuration(ctx.String("older-than"))
+	if err != nil {
+		return cli.Exit(err.Error(), 1)
+	}
+	manager := internal.NewSdkManagerWithSource()
+	defer manager.Close()
+	pruned, err := manager.DownloadCache.Prune(olderThan)
+	if err != nil {
+		return fmt.Errorf("prune cache failed, err: %w", err)
+	}
+	var reclaimed int64
+	for _, entry := range pruned {
+		reclaimed += entry.Size
+		pterm.Printf("Removed %s\n", entry.Filename)
+	}
+	pterm.Printf("Pruned %d file(s), %s reclaimed.\n", len(pruned), pterm.LightGreen(util.FormatSize(reclaimed)))
+	return nil
+}
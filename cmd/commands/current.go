@@ -18,9 +18,12 @@ package commands
 
 import (
 	"fmt"
+	"sort"
+
 	"github.com/pterm/pterm"
 	"github.com/urfave/cli/v2"
 	"github.com/version-fox/vfox/internal"
+	"github.com/version-fox/vfox/internal/env"
 )
 
 var Current = &cli.Command{
@@ -31,6 +34,13 @@ var Current = &cli.Command{
 	Action:    currentCmd,
 }
 
+// currentItem is the structured output of `vfox current`, the version is empty if none is in use.
+type currentItem struct {
+	Name    string `json:"name" yaml:"name"`
+	Version string `json:"version" yaml:"version"`
+	Origin  string `json:"origin" yaml:"origin"`
+}
+
 func currentCmd(ctx *cli.Context) error {
 	manager := internal.NewSdkManager(internal.GlobalRecordSource, internal.SessionRecordSource, internal.ProjectRecordSource)
 	defer manager.Close()
@@ -40,12 +50,24 @@ func currentCmd(ctx *cli.Context) error {
 		if err != nil {
 			return err
 		}
+		if isStructured() {
+			names := make([]string, 0, len(allSdk))
+			for name := range allSdk {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			items := make([]currentItem, 0, len(names))
+			for _, name := range names {
+				items = append(items, newCurrentItem(manager, name, allSdk[name]))
+			}
+			return printStructured(items)
+		}
 		for name, s := range allSdk {
 			current := s.Current()
 			if current == "" {
 				pterm.Printf("%s -> N/A \n", name)
 			} else {
-				pterm.Printf("%s -> %s\n", name, pterm.LightGreen("v"+string(current)))
+				pterm.Printf("%s -> %s %s\n", name, pterm.LightGreen(currentLabel(current)), pterm.Gray("("+manager.Record.Origin(s.Plugin.SdkName)+")"))
 			}
 		}
 		return nil
@@ -58,6 +80,24 @@ func currentCmd(ctx *cli.Context) error {
 	if current == "" {
 		return fmt.Errorf("no current version of %s", sdkName)
 	}
-	pterm.Println("->", pterm.LightGreen("v"+string(current)))
+	if isStructured() {
+		return printStructured([]currentItem{newCurrentItem(manager, sdkName, source)})
+	}
+	pterm.Println("->", pterm.LightGreen(currentLabel(current)), pterm.Gray("("+manager.Record.Origin(source.Plugin.SdkName)+")"))
 	return nil
 }
+
+func currentLabel(version internal.Version) string {
+	if _, ok := env.PathVersion(string(version)); ok || version == env.SystemVersion {
+		return string(version)
+	}
+	return "v" + string(version)
+}
+
+func newCurrentItem(manager *internal.Manager, name string, sdk *internal.Sdk) currentItem {
+	item := currentItem{Name: name, Version: string(sdk.Current())}
+	if item.Version != "" {
+		item.Origin = manager.Record.Origin(sdk.Plugin.SdkName)
+	}
+	return item
+}
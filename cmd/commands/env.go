@@ -65,9 +65,9 @@ func envCmd(ctx *cli.Context) error {
 		}
 		manager := internal.NewSdkManagerWithSource(internal.GlobalRecordSource, internal.SessionRecordSource, internal.ProjectRecordSource)
 		defer manager.Close()
-		for k, v := range manager.Record.Export() {
+		for k := range manager.Record.Export() {
 			if lookupSdk, err := manager.LookupSdk(k); err == nil {
-				if keys, err := lookupSdk.EnvKeys(internal.Version(v)); err == nil {
+				if keys, err := lookupSdk.EnvKeys(lookupSdk.Current()); err == nil {
 					data.SDKs[lookupSdk.Plugin.Name] = keys.Variables
 					data.Paths = append(data.Paths, keys.Paths...)
 				}
@@ -106,8 +106,9 @@ func envCmd(ctx *cli.Context) error {
 			exportEnvs[k] = v
 		}
 		sdkPaths := envKeys.Paths
-		if len(sdkPaths) != 0 {
-			originPath := os.Getenv(env.PathFlag)
+		originPath := os.Getenv(env.PathFlag)
+		// Reset PATH even without sdk paths, to drop the paths of the sdks no longer used, such as the system version.
+		if len(sdkPaths) != 0 || originPath != "" {
 			paths := manager.EnvManager.Paths(append(sdkPaths[:], originPath))
 			exportEnvs["PATH"] = &paths
 		}
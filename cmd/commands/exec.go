@@ -0,0 +1,129 @@
+/*
+ *    Copyright 2024 Han Li and contributors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/urfave/cli/v2"
+	"github.com/version-fox/vfox/internal"
+	"github.com/version-fox/vfox/internal/env"
+)
+
+var Exec = &cli.Command{
+	Name:            "exec",
+	Usage:           "run a command with the specified sdk versions",
+	UsageText:       "vfox exec [<sdk-name>@<version>...] -- <command> [args...]",
+	SkipFlagParsing: true,
+	Action:          execCmd,
+}
+
+func execCmd(ctx *cli.Context) error {
+	sdkArgs, command := splitExecArgs(ctx.Args().Slice())
+	if len(command) == 0 {
+		return cli.Exit("no command to run, usage: "+ctx.Command.UsageText, 1)
+	}
+	var args []internal.Arg
+	for _, sdkArg := range sdkArgs {
+		name, version, _ := strings.Cut(sdkArg, "@")
+		args = append(args, internal.Arg{Name: strings.ToLower(name), Version: version})
+	}
+
+	manager := internal.NewSdkManager(internal.GlobalRecordSource, internal.SessionRecordSource, internal.ProjectRecordSource)
+	var (
+		envs *env.Envs
+		err  error
+	)
+	if len(args) == 0 {
+		envs, err = manager.EnvKeys()
+	} else {
+		envs, err = manager.SdkEnvKeys(args)
+	}
+	// The lua plugins are not needed any more, close them before running the command.
+	manager.Close()
+	if err != nil {
+		return err
+	}
+	return runCommand(envs.Environ(os.Environ()), command)
+}
+
+// splitExecArgs splits the arguments at --, the sdks are before it and the command is after it.
+// All arguments are the command if there is no --, e.g. `vfox exec node -v` runs `node -v`
+// with the recorded versions.
+func splitExecArgs(args []string) ([]string, []string) {
+	for i, arg := range args {
+		if arg == "--" {
+			return args[:i], args[i+1:]
+		}
+	}
+	return nil, args
+}
+
+// runCommand runs the command with the environment, passing through stdio and signals,
+// and exits with the exit code of the command.
+func runCommand(environ []string, command []string) error {
+	// The command is looked up in the PATH of the new environment.
+	for _, kv := range environ {
+		if key, value, _ := strings.Cut(kv, "="); strings.EqualFold(key, "PATH") {
+			_ = os.Setenv(key, value)
+		}
+	}
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Env = environ
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		return cli.Exit(fmt.Sprintf("failed to run %s, err: %s", command[0], err), 127)
+	}
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, forwardSignals...)
+	defer signal.Stop(sigs)
+	go func() {
+		for sig := range sigs {
+			// The terminal sends the interrupt to the whole process group,
+			// the command has received it already.
+			if sig == os.Interrupt {
+				continue
+			}
+			_ = cmd.Process.Signal(sig)
+		}
+	}()
+
+	err := cmd.Wait()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return cli.Exit("", exitCode(exitErr))
+	}
+	return err
+}
+
+// exitCode returns the exit code of the command, it is 128 plus the signal number
+// for a command killed by a signal, like in the shells.
+func exitCode(exitErr *exec.ExitError) int {
+	if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return 128 + int(status.Signal())
+	}
+	return exitErr.ExitCode()
+}
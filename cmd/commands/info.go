@@ -29,6 +29,17 @@ var Info = &cli.Command{
 	Action: infoCmd,
 }
 
+// infoItem is the structured output of `vfox info`.
+type infoItem struct {
+	Name              string   `json:"name" yaml:"name"`
+	Author            string   `json:"author" yaml:"author"`
+	Version           string   `json:"version" yaml:"version"`
+	Description       string   `json:"description" yaml:"description"`
+	UpdateUrl         string   `json:"updateUrl" yaml:"updateUrl"`
+	MinRuntimeVersion string   `json:"minRuntimeVersion" yaml:"minRuntimeVersion"`
+	LegacyFilenames   []string `json:"legacyFilenames" yaml:"legacyFilenames"`
+}
+
 func infoCmd(ctx *cli.Context) error {
 	manager := internal.NewSdkManager()
 	defer manager.Close()
@@ -41,6 +52,21 @@ func infoCmd(ctx *cli.Context) error {
 		return fmt.Errorf("%s not supported, error: %w", args, err)
 	}
 	source := s.Plugin
+	if isStructured() {
+		legacyFilenames := source.LegacyFilenames
+		if legacyFilenames == nil {
+			legacyFilenames = []string{}
+		}
+		return printStructured(infoItem{
+			Name:              source.Name,
+			Author:            source.Author,
+			Version:           source.Version,
+			Description:       source.Description,
+			UpdateUrl:         source.UpdateUrl,
+			MinRuntimeVersion: source.MinRuntimeVersion,
+			LegacyFilenames:   legacyFilenames,
+		})
+	}
 
 	pterm.Println("Plugin info:")
 	pterm.Println("Name     ", "->", pterm.LightBlue(source.Name))
@@ -17,23 +17,51 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pterm/pterm"
 	"github.com/urfave/cli/v2"
 	"github.com/version-fox/vfox/internal"
-	"strings"
 )
 
 var Install = &cli.Command{
-	Name:    "install",
-	Aliases: []string{"i"},
-	Usage:   "install a version of sdk",
-	Action:  installCmd,
+	Name:      "install",
+	Aliases:   []string{"i"},
+	Usage:     "install a version of sdk",
+	UsageText: "install all sdks recorded in .tool-versions if no parameters are passed",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:    "all",
+			Aliases: []string{"a"},
+			Usage:   "install all sdks recorded in .tool-versions",
+		},
+		&cli.BoolFlag{
+			Name:  "frozen",
+			Usage: "install exactly the files locked in .tool-versions.lock, fail on any mismatch",
+		},
+	},
+	Action: installCmd,
+}
+
+// installItem is the structured output of `vfox install`, the status is one of installed, skipped and failed.
+type installItem struct {
+	Name    string `json:"name" yaml:"name"`
+	Version string `json:"version" yaml:"version"`
+	Status  string `json:"status" yaml:"status"`
+	Error   string `json:"error,omitempty" yaml:"error,omitempty"`
 }
 
 func installCmd(ctx *cli.Context) error {
 	sdkArg := ctx.Args().First()
-	if sdkArg == "" {
-		return cli.Exit("sdk name is required", 1)
+	frozen := ctx.Bool("frozen")
+	if ctx.Bool("all") && sdkArg != "" {
+		return cli.Exit("--all can not be used with a sdk name", 1)
+	}
+	if sdkArg == "" || ctx.Bool("all") {
+		return installAll(frozen)
 	}
 	argArr := strings.Split(sdkArg, "@")
 	argsLen := len(argArr)
@@ -51,6 +79,16 @@ func installCmd(ctx *cli.Context) error {
 			name = strings.ToLower(argArr[0])
 			version = ""
 		}
+		args := []internal.Arg{{Name: name, Version: string(version)}}
+		if frozen {
+			if isStructured() {
+				return printInstallResult(args, manager.InstallFrozen(args))
+			}
+			return manager.InstallFrozen(args)[0]
+		}
+		if isStructured() {
+			return printInstallResult(args, manager.InstallAll(args))
+		}
 		source, err := manager.LookupSdk(name)
 		if err != nil {
 			return fmt.Errorf("%s not supported, error: %w", name, err)
@@ -58,3 +96,91 @@ func installCmd(ctx *cli.Context) error {
 		return source.Install(version)
 	}
 }
+
+// installAll installs all sdks recorded in the project and global .tool-versions which are not installed yet.
+// In frozen mode, the sdks are installed exactly as locked in .tool-versions.lock.
+func installAll(frozen bool) error {
+	manager := internal.NewSdkManager(internal.GlobalRecordSource, internal.ProjectRecordSource)
+	defer manager.Close()
+
+	records := manager.Record.Export()
+	if len(records) == 0 {
+		if isStructured() {
+			return printStructured([]installItem{})
+		}
+		pterm.Println("No sdk recorded in .tool-versions, nothing to install.")
+		return nil
+	}
+	names := make([]string, 0, len(records))
+	for name := range records {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	args := make([]internal.Arg, 0, len(names))
+	for _, name := range names {
+		args = append(args, internal.Arg{Name: name, Version: records[name]})
+	}
+
+	var errs []error
+	if frozen {
+		errs = manager.InstallFrozen(args)
+	} else {
+		errs = manager.InstallMissing(args)
+	}
+	if isStructured() {
+		return printInstallResult(args, errs)
+	}
+	var installed, skipped, failed []string
+	for i, err := range errs {
+		label := fmt.Sprintf("%s@%s", args[i].Name, args[i].Version)
+		if err == nil {
+			installed = append(installed, label)
+		} else if errors.Is(err, internal.ErrAlreadyInstalled) {
+			skipped = append(skipped, label)
+		} else {
+			pterm.Printf("Install %s failed, error: %s\n", pterm.Red(label), err)
+			failed = append(failed, label)
+		}
+	}
+
+	pterm.Println()
+	pterm.Printf("Installed: %s\n", pterm.LightGreen(summary(installed)))
+	pterm.Printf("Skipped  : %s\n", pterm.LightBlue(summary(skipped)))
+	pterm.Printf("Failed   : %s\n", pterm.Red(summary(failed)))
+	if len(failed) > 0 {
+		return cli.Exit(fmt.Sprintf("failed to install %d sdk(s)", len(failed)), 1)
+	}
+	return nil
+}
+
+// printInstallResult prints the structured result of the installation, it fails if any sdk failed.
+func printInstallResult(args []internal.Arg, errs []error) error {
+	items := make([]installItem, 0, len(args))
+	failed := 0
+	for i, err := range errs {
+		item := installItem{Name: args[i].Name, Version: args[i].Version, Status: "installed"}
+		if errors.Is(err, internal.ErrAlreadyInstalled) {
+			item.Status = "skipped"
+		} else if err != nil {
+			item.Status = "failed"
+			item.Error = err.Error()
+			failed++
+		}
+		items = append(items, item)
+	}
+	if err := printStructured(items); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return cli.Exit(fmt.Sprintf("failed to install %d sdk(s)", failed), 1)
+	}
+	return nil
+}
+
+func summary(labels []string) string {
+	if len(labels) == 0 {
+		return "-"
+	}
+	return strings.Join(labels, ", ")
+}
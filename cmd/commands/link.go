@@ -0,0 +1,51 @@
+/*
+ *    Copyright 2024 Han Li and contributors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+	"github.com/version-fox/vfox/internal"
+)
+
+var Link = &cli.Command{
+	Name:      "link",
+	Usage:     "register a directory installed outside vfox as a version of sdk",
+	UsageText: "vfox link <sdk-name>@<version> <path>",
+	Action:    linkCmd,
+}
+
+func linkCmd(ctx *cli.Context) error {
+	args := ctx.Args()
+	if args.Len() != 2 {
+		return cli.Exit("invalid arguments, usage: "+ctx.Command.UsageText, 1)
+	}
+	name, version, ok := strings.Cut(args.First(), "@")
+	if !ok || version == "" {
+		return cli.Exit("sdk version is required", 1)
+	}
+	name = strings.ToLower(name)
+	manager := internal.NewSdkManager()
+	defer manager.Close()
+	source, err := manager.LookupSdk(name)
+	if err != nil {
+		return fmt.Errorf("%s not supported, error: %w", name, err)
+	}
+	return source.Link(internal.Version(version), args.Get(1))
+}
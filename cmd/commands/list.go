@@ -18,6 +18,8 @@ package commands
 
 import (
 	"fmt"
+	"sort"
+
 	"github.com/pterm/pterm"
 	"github.com/pterm/pterm/putils"
 	"github.com/urfave/cli/v2"
@@ -31,6 +33,21 @@ var List = &cli.Command{
 	Action:  listCmd,
 }
 
+// listItem is the structured output of `vfox list`.
+type listItem struct {
+	Name     string   `json:"name" yaml:"name"`
+	Current  string   `json:"current" yaml:"current"`
+	Versions []string `json:"versions" yaml:"versions"`
+}
+
+func newListItem(name string, sdk *internal.Sdk) listItem {
+	item := listItem{Name: name, Current: string(sdk.Current()), Versions: []string{}}
+	for _, version := range sdk.List() {
+		item.Versions = append(item.Versions, string(version))
+	}
+	return item
+}
+
 func listCmd(ctx *cli.Context) error {
 	manager := internal.NewSdkManager()
 	defer manager.Close()
@@ -40,6 +57,18 @@ func listCmd(ctx *cli.Context) error {
 		if err != nil {
 			return err
 		}
+		if isStructured() {
+			names := make([]string, 0, len(allSdk))
+			for name := range allSdk {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			items := make([]listItem, 0, len(names))
+			for _, name := range names {
+				items = append(items, newListItem(name, allSdk[name]))
+			}
+			return printStructured(items)
+		}
 		if len(allSdk) == 0 {
 			return fmt.Errorf("you don't have any sdk installed yet")
 		}
@@ -61,6 +90,9 @@ func listCmd(ctx *cli.Context) error {
 	if err != nil {
 		return fmt.Errorf("%s not supported, error: %w", sdkName, err)
 	}
+	if isStructured() {
+		return printStructured([]listItem{newListItem(sdkName, source)})
+	}
 	curVersion := source.Current()
 	list := source.List()
 	if len(list) == 0 {
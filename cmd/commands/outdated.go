@@ -0,0 +1,219 @@
+/*
+ *    Copyright 2024 Han Li and contributors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/pterm/pterm"
+	"github.com/urfave/cli/v2"
+	"github.com/version-fox/vfox/internal"
+)
+
+var Outdated = &cli.Command{
+	Name:      "outdated",
+	Usage:     "show the newer versions of the installed sdks",
+	UsageText: "vfox outdated [<sdk-name>...]",
+	Action:    outdatedCmd,
+}
+
+var Upgrade = &cli.Command{
+	Name:      "upgrade",
+	Usage:     "upgrade a sdk to the newest version",
+	UsageText: "vfox upgrade [--patch|--minor] [--uninstall] <sdk-name>",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "patch",
+			Usage: "only upgrade to a newer patch version",
+		},
+		&cli.BoolFlag{
+			Name:  "minor",
+			Usage: "only upgrade to a newer minor or patch version",
+		},
+		&cli.BoolFlag{
+			Name:  "uninstall",
+			Usage: "uninstall the old version after upgrading",
+		},
+	},
+	Action: upgradeCmd,
+}
+
+// outdatedItem is the structured output of `vfox outdated`, a newer version is empty if there is none.
+type outdatedItem struct {
+	Name    string `json:"name" yaml:"name"`
+	Current string `json:"current" yaml:"current"`
+	Patch   string `json:"patch" yaml:"patch"`
+	Minor   string `json:"minor" yaml:"minor"`
+	Major   string `json:"major" yaml:"major"`
+}
+
+func outdatedCmd(ctx *cli.Context) error {
+	manager := internal.NewSdkManager(internal.GlobalRecordSource, internal.SessionRecordSource, internal.ProjectRecordSource)
+	defer manager.Close()
+	names := ctx.Args().Slice()
+	if len(names) == 0 {
+		allSdk, err := manager.LoadAllSdk()
+		if err != nil {
+			return err
+		}
+		for name := range allSdk {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+	items := []outdatedItem{}
+	for _, name := range names {
+		source, err := manager.LookupSdk(name)
+		if err != nil {
+			return fmt.Errorf("%s not supported, error: %w", name, err)
+		}
+		outdated, err := source.Outdated()
+		if err != nil {
+			pterm.Printf("Failed to check %s, err: %s\n", pterm.Red(name), err)
+			continue
+		}
+		if outdated == nil || !outdated.IsOutdated() {
+			continue
+		}
+		items = append(items, outdatedItem{
+			Name:    name,
+			Current: string(outdated.Current),
+			Patch:   string(outdated.Patch),
+			Minor:   string(outdated.Minor),
+			Major:   string(outdated.Major),
+		})
+	}
+	if isStructured() {
+		return printStructured(items)
+	}
+	if len(items) == 0 {
+		pterm.Println("All sdks are up to date.")
+		return nil
+	}
+	data := pterm.TableData{
+		{"SDK", "CURRENT", "PATCH", "MINOR", "MAJOR"},
+	}
+	for _, item := range items {
+		data = append(data, []string{
+			item.Name,
+			item.Current,
+			versionOrDash(item.Patch),
+			versionOrDash(item.Minor),
+			versionOrDash(item.Major),
+		})
+	}
+	return pterm.DefaultTable.
+		WithHasHeader().
+		WithSeparator("\t ").
+		WithData(data).Render()
+}
+
+func versionOrDash(version string) string {
+	if version == "" {
+		return "-"
+	}
+	return pterm.LightGreen(version)
+}
+
+func upgradeCmd(ctx *cli.Context) error {
+	name := ctx.Args().First()
+	if name == "" {
+		return cli.Exit("sdk name is required", 1)
+	}
+	if ctx.NArg() > 1 {
+		return cli.Exit("the options must be placed before the sdk name, usage: "+ctx.Command.UsageText, 1)
+	}
+	patch, minor, uninstall := ctx.Bool("patch"), ctx.Bool("minor"), ctx.Bool("uninstall")
+	level := internal.MajorUpgrade
+	if patch {
+		level = internal.PatchUpgrade
+	} else if minor {
+		level = internal.MinorUpgrade
+	}
+
+	manager := internal.NewSdkManager(internal.GlobalRecordSource, internal.SessionRecordSource, internal.ProjectRecordSource)
+	source, err := manager.LookupSdk(name)
+	if err != nil {
+		manager.Close()
+		return fmt.Errorf("%s not supported, error: %w", name, err)
+	}
+	outdated, err := source.Outdated()
+	if err != nil {
+		manager.Close()
+		return err
+	}
+	if outdated == nil {
+		manager.Close()
+		return cli.Exit(fmt.Sprintf("no version of %s is installed", name), 1)
+	}
+	target := outdated.Target(level)
+	if target == "" {
+		manager.Close()
+		pterm.Printf("%s@%s is up to date.\n", name, outdated.Current)
+		return nil
+	}
+	if err = source.Install(target); err != nil && !errors.Is(err, internal.ErrAlreadyInstalled) {
+		manager.Close()
+		return err
+	}
+	origin, scope := manager.PinnedOrigin(source.Plugin.SdkName)
+	manager.Close()
+
+	if origin != "" {
+		if err = pinUpgraded(name, target, origin, scope); err != nil {
+			return err
+		}
+	}
+	if !uninstall {
+		return nil
+	}
+	manager = internal.NewSdkManager(internal.GlobalRecordSource, internal.SessionRecordSource, internal.ProjectRecordSource)
+	defer manager.Close()
+	source, err = manager.LookupSdk(name)
+	if err != nil {
+		return err
+	}
+	if source.IsReferenced(outdated.Current) {
+		pterm.Printf("%s@%s is still used by a record, skip uninstalling it.\n", name, outdated.Current)
+		return nil
+	}
+	return source.Uninstall(outdated.Current)
+}
+
+// pinUpgraded switches the record which pinned the old version to the target version. The global and
+// session records are switched like `vfox use`, and the .tool-versions of a project is rewritten in place,
+// even if it is in a parent directory.
+func pinUpgraded(name string, target internal.Version, origin string, scope internal.UseScope) error {
+	if scope == internal.Project {
+		manager := internal.NewSdkManager()
+		defer manager.Close()
+		source, err := manager.LookupSdk(name)
+		if err != nil {
+			return err
+		}
+		return source.PinAt(target, origin)
+	}
+	manager := internal.NewSdkManagerWithSource(scopeRecordSources(scope)...)
+	defer manager.Close()
+	source, err := manager.LookupSdk(name)
+	if err != nil {
+		return err
+	}
+	return source.Pin(target, scope)
+}
@@ -0,0 +1,101 @@
+/*
+ *    Copyright 2024 Han Li and contributors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/pterm/pterm"
+	"github.com/urfave/cli/v2"
+	"github.com/version-fox/vfox/internal/printer"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	tableOutput = "table"
+	jsonOutput  = "json"
+	yamlOutput  = "yaml"
+)
+
+// outputFormat is selected by the --output flag, which can be set globally or on any command.
+var outputFormat = tableOutput
+
+// OutputFlag returns a new --output flag. Each command needs its own flag,
+// because the flag remembers whether it has been set.
+func OutputFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:    "output",
+		Aliases: []string{"o"},
+		Usage:   "output format, one of table, json and yaml",
+		Value:   tableOutput,
+		Action: func(ctx *cli.Context, format string) error {
+			return setOutputFormat(format)
+		},
+	}
+}
+
+// WithOutputFlag adds the --output flag to the command and its subcommands.
+func WithOutputFlag(command *cli.Command) {
+	command.Flags = append(command.Flags, OutputFlag())
+	for _, sub := range command.Subcommands {
+		WithOutputFlag(sub)
+	}
+}
+
+func setOutputFormat(format string) error {
+	switch format {
+	case tableOutput:
+	case jsonOutput, yamlOutput:
+		// Keep the stdout clean for the structured output, the messages go to stderr.
+		pterm.SetDefaultOutput(os.Stderr)
+		printer.Interactive = false
+	default:
+		return cli.Exit(fmt.Sprintf("unsupported output format: %s, must be one of table, json and yaml", format), 1)
+	}
+	outputFormat = format
+	return nil
+}
+
+// isStructured reports whether a machine-readable output is selected.
+func isStructured() bool {
+	return outputFormat != tableOutput
+}
+
+// printStructured prints the value in the selected machine-readable format.
+func printStructured(v any) error {
+	if outputFormat == yamlOutput {
+		encoder := yaml.NewEncoder(os.Stdout)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(v); err != nil {
+			return err
+		}
+		return encoder.Close()
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// requireInteractive fails if a prompt is needed while a machine-readable output is selected.
+func requireInteractive(hint string) error {
+	if isStructured() {
+		return cli.Exit(fmt.Sprintf("can not prompt with the %s output, %s", outputFormat, hint), 1)
+	}
+	return nil
+}
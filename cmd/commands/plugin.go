@@ -0,0 +1,41 @@
+/*
+ *    Copyright 2024 Han Li and contributors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package commands
+
+import (
+	"github.com/urfave/cli/v2"
+	"github.com/version-fox/vfox/internal"
+)
+
+var Plugin = &cli.Command{
+	Name:  "plugin",
+	Usage: "manage plugins",
+	Subcommands: []*cli.Command{
+		{
+			Name:      "sync",
+			Usage:     "install exactly the plugins recorded in vfox.lock",
+			UsageText: "vfox plugin sync [<lockfile>]",
+			Action:    pluginSyncCmd,
+		},
+	},
+}
+
+func pluginSyncCmd(ctx *cli.Context) error {
+	manager := internal.NewSdkManagerWithSource()
+	defer manager.Close()
+	return manager.SyncPlugins(ctx.Args().First())
+}
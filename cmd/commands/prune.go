@@ -0,0 +1,104 @@
+/*
+ *    Copyright 2024 Han Li and contributors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/urfave/cli/v2"
+	"github.com/version-fox/vfox/internal"
+	"github.com/version-fox/vfox/internal/util"
+)
+
+var Prune = &cli.Command{
+	Name:      "prune",
+	Usage:     "uninstall the versions which are not used by any record",
+	UsageText: "vfox prune [options] [<sdk-name>...]",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "only show the versions to be uninstalled",
+		},
+		&cli.IntFlag{
+			Name:  "keep-latest",
+			Usage: "keep the `N` highest versions of each sdk",
+		},
+		&cli.StringSliceFlag{
+			Name:  "project-root",
+			Usage: "search the `DIR` for .tool-versions in addition to the configured project roots",
+		},
+		&cli.BoolFlag{
+			Name:    "yes",
+			Aliases: []string{"y"},
+			Usage:   "uninstall without confirmation",
+		},
+	},
+	Action: pruneCmd,
+}
+
+func pruneCmd(ctx *cli.Context) error {
+	var sdks []string
+	for _, name := range ctx.Args().Slice() {
+		sdks = append(sdks, strings.ToLower(name))
+	}
+	manager := internal.NewSdkManager(internal.GlobalRecordSource, internal.SessionRecordSource, internal.ProjectRecordSource)
+	defer manager.Close()
+	candidates, err := manager.PruneCandidates(&internal.PruneOptions{
+		Sdks:         sdks,
+		KeepLatest:   ctx.Int("keep-latest"),
+		ProjectRoots: ctx.StringSlice("project-root"),
+	})
+	if err != nil {
+		return err
+	}
+	if len(candidates) == 0 {
+		pterm.Println("No unused version to prune.")
+		return nil
+	}
+	var total int64
+	for _, candidate := range candidates {
+		total += candidate.Size
+		label := fmt.Sprintf("%s@%s", strings.ToLower(candidate.Sdk.Plugin.SdkName), candidate.Version)
+		pterm.Printf("%s %s\n", label, pterm.Gray("("+util.FormatSize(candidate.Size)+")"))
+	}
+	if ctx.Bool("dry-run") {
+		pterm.Printf("%d version(s) would be uninstalled, %s would be reclaimed.\n", len(candidates), pterm.LightGreen(util.FormatSize(total)))
+		return nil
+	}
+	if !ctx.Bool("yes") {
+		if err = requireInteractive("use --yes or --dry-run"); err != nil {
+			return err
+		}
+		result, _ := pterm.DefaultInteractiveConfirm.
+			WithTextStyle(&pterm.ThemeDefault.DefaultText).
+			WithConfirmStyle(&pterm.ThemeDefault.DefaultText).
+			WithRejectStyle(&pterm.ThemeDefault.DefaultText).
+			WithDefaultText(fmt.Sprintf("Uninstall the %d version(s) above?", len(candidates))).
+			Show()
+		if !result {
+			return cli.Exit("prune canceled", 1)
+		}
+	}
+	reclaimed, err := manager.Prune(candidates)
+	if err != nil {
+		return fmt.Errorf("prune failed, err: %w", err)
+	}
+	pterm.Printf("Pruned %d version(s), %s reclaimed.\n", len(candidates), pterm.LightGreen(util.FormatSize(reclaimed)))
+	return nil
+}
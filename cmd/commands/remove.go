@@ -34,6 +34,9 @@ func removeCmd(ctx *cli.Context) error {
 	if l < 1 {
 		return cli.Exit("invalid arguments", 1)
 	}
+	if err := requireInteractive("the removal needs to be confirmed"); err != nil {
+		return err
+	}
 	manager := internal.NewSdkManager()
 	defer manager.Close()
 	pterm.Println("Removing this plugin will remove the installed sdk along with the plugin.")
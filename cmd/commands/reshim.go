@@ -0,0 +1,66 @@
+/*
+ *    Copyright 2024 Han Li and contributors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package commands
+
+import (
+	"os"
+
+	"github.com/pterm/pterm"
+	"github.com/urfave/cli/v2"
+	"github.com/version-fox/vfox/internal"
+)
+
+var Reshim = &cli.Command{
+	Name:   "reshim",
+	Usage:  "regenerate the shims of the installed sdks",
+	Action: reshimCmd,
+}
+
+// Shim is called by the shims, it runs the binary of the version resolved from
+// the project, session and global records.
+var Shim = &cli.Command{
+	Name:            "shim",
+	Hidden:          true,
+	SkipFlagParsing: true,
+	Action:          shimCmd,
+}
+
+func reshimCmd(ctx *cli.Context) error {
+	manager := internal.NewSdkManagerWithSource()
+	defer manager.Close()
+	names, err := manager.Reshim()
+	if err != nil {
+		return err
+	}
+	pterm.Printf("Generated %d shim(s) in %s\n", len(names), pterm.LightBlue(manager.PathMeta.ShimsPath))
+	pterm.Println("Please add it to your PATH to use the shims.")
+	return nil
+}
+
+func shimCmd(ctx *cli.Context) error {
+	args := ctx.Args().Slice()
+	if len(args) == 0 {
+		return cli.Exit("shim name is required", 1)
+	}
+	manager := internal.NewSdkManager(internal.GlobalRecordSource, internal.SessionRecordSource, internal.ProjectRecordSource)
+	bin, envs, err := manager.ShimTarget(args[0])
+	manager.Close()
+	if err != nil {
+		return cli.Exit(err.Error(), 127)
+	}
+	return runCommand(envs.Environ(os.Environ()), append([]string{bin}, args[1:]...))
+}
@@ -18,36 +18,142 @@ package commands
 
 import (
 	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pterm/pterm"
 	"github.com/urfave/cli/v2"
 	"github.com/version-fox/vfox/internal"
 	"github.com/version-fox/vfox/internal/printer"
 	"golang.org/x/crypto/ssh/terminal"
-	"math"
-	"os"
-	"strings"
 )
 
 var Search = &cli.Command{
-	Name:   "search",
-	Usage:  "search a version of sdk",
+	Name:      "search",
+	Usage:     "search a version of sdk",
+	UsageText: "vfox search [--filter <filter>] [--limit <n>] [--no-interactive] <sdk-name> [<args>...]",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "filter",
+			Usage: "only show the versions matching a version constraint or an alias, such as 20 or lts",
+		},
+		&cli.IntFlag{
+			Name:  "limit",
+			Usage: "show at most the number of versions",
+		},
+		&cli.BoolFlag{
+			Name:  "no-interactive",
+			Usage: "list the versions instead of selecting one to install",
+		},
+	},
 	Action: searchCmd,
 }
 
+// searchItem is the structured output of `vfox search`.
+type searchItem struct {
+	Version   string               `json:"version" yaml:"version"`
+	Note      string               `json:"note" yaml:"note"`
+	Aliases   []string             `json:"aliases" yaml:"aliases"`
+	Additions []searchAdditionItem `json:"additions" yaml:"additions"`
+	Installed bool                 `json:"installed" yaml:"installed"`
+	Current   bool                 `json:"current" yaml:"current"`
+}
+
+type searchAdditionItem struct {
+	Name    string `json:"name" yaml:"name"`
+	Version string `json:"version" yaml:"version"`
+}
+
+// searchOptions are the flags of `vfox search`.
+type searchOptions struct {
+	filter        string
+	limit         int
+	noInteractive bool
+	// args are passed to the Available hook of the plugin
+	args []string
+}
+
+func parseSearchOptions(ctx *cli.Context) (*searchOptions, error) {
+	opts := &searchOptions{
+		filter:        ctx.String("filter"),
+		limit:         ctx.Int("limit"),
+		noInteractive: ctx.Bool("no-interactive"),
+	}
+	if opts.limit < 0 {
+		return nil, cli.Exit("invalid limit: "+strconv.Itoa(opts.limit), 1)
+	}
+	// The arguments after the sdk name are passed to the plugin, the ones looking like
+	// flags must follow `--`, e.g. `vfox search java -- --all`.
+	tail := ctx.Args().Tail()
+	for i, arg := range tail {
+		if arg == "--" {
+			opts.args = append(opts.args, tail[i+1:]...)
+			break
+		}
+		if strings.HasPrefix(arg, "-") {
+			return nil, cli.Exit(fmt.Sprintf("the options must be placed before the sdk name, or after -- to pass %s to the plugin", arg), 1)
+		}
+		opts.args = append(opts.args, arg)
+	}
+	// Nothing can be selected if the output is machine-readable or not a terminal.
+	if isStructured() || !terminal.IsTerminal(int(os.Stdout.Fd())) {
+		opts.noInteractive = true
+	}
+	return opts, nil
+}
+
 func searchCmd(ctx *cli.Context) error {
 	sdkName := ctx.Args().First()
 	if sdkName == "" {
 		return cli.Exit("sdk name is required", 1)
 	}
+	opts, err := parseSearchOptions(ctx)
+	if err != nil {
+		return err
+	}
 	manager := internal.NewSdkManager()
 	defer manager.Close()
 	source, err := manager.LookupSdk(sdkName)
 	if err != nil {
 		return fmt.Errorf("%s not supported, error: %w", sdkName, err)
 	}
-	result, err := source.Available()
+	result, err := source.Available(opts.args...)
 	if err != nil {
 		return fmt.Errorf("plugin [Available] method error: %w", err)
 	}
+	result = internal.FilterPackages(result, opts.filter)
+	if opts.limit > 0 && len(result) > opts.limit {
+		result = result[:opts.limit]
+	}
+	current := source.Current()
+	installed := make(map[internal.Version]bool)
+	for _, version := range source.List() {
+		installed[version] = true
+	}
+
+	if isStructured() {
+		items := make([]searchItem, 0, len(result))
+		for _, p := range result {
+			item := searchItem{
+				Version:   string(p.Main.Version),
+				Note:      p.Main.Note,
+				Aliases:   p.Aliases,
+				Additions: []searchAdditionItem{},
+				Installed: installed[p.Main.Version],
+				Current:   p.Main.Version == current,
+			}
+			if item.Aliases == nil {
+				item.Aliases = []string{}
+			}
+			for _, a := range p.Additions {
+				item.Additions = append(item.Additions, searchAdditionItem{Name: a.Name, Version: string(a.Version)})
+			}
+			items = append(items, item)
+		}
+		return printStructured(items)
+	}
 	if len(result) == 0 {
 		return fmt.Errorf("no available version")
 	}
@@ -67,12 +173,25 @@ func searchCmd(ctx *cli.Context) error {
 			}
 			value = fmt.Sprintf("%s [%s]", value, strings.Join(additional, ","))
 		}
+		if installed[p.Main.Version] {
+			value = fmt.Sprintf("%s %s", value, pterm.LightBlue("(installed)"))
+		}
+		if p.Main.Version == current {
+			value = fmt.Sprintf("%s %s", value, pterm.LightGreen("<— current"))
+		}
 		options = append(options, &printer.KV{
 			Key:   string(p.Main.Version),
 			Value: value,
 		})
 	}
 
+	if opts.noInteractive {
+		for _, option := range options {
+			pterm.Println("->", option.Value)
+		}
+		return nil
+	}
+
 	_, height, _ := terminal.GetSize(int(os.Stdout.Fd()))
 	kvSelect := printer.PageKVSelect{
 		TopText: "Please select a version of " + sdkName,
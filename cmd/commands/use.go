@@ -69,19 +69,13 @@ func useCmd(ctx *cli.Context) error {
 		version = internal.Version(argArr[1])
 	}
 
-	var recordSources []internal.RecordSource
 	scope := internal.Session
 	if ctx.IsSet("global") {
 		scope = internal.Global
-		recordSources = append(recordSources, internal.SessionRecordSource, internal.GlobalRecordSource)
 	} else if ctx.IsSet("project") {
 		scope = internal.Project
-		recordSources = append(recordSources, internal.ProjectRecordSource)
-	} else {
-		scope = internal.Session
-		recordSources = append(recordSources, internal.SessionRecordSource)
 	}
-	manager := internal.NewSdkManagerWithSource(recordSources...)
+	manager := internal.NewSdkManagerWithSource(scopeRecordSources(scope)...)
 	defer manager.Close()
 
 	source, err := manager.LookupSdk(name)
@@ -90,6 +84,9 @@ func useCmd(ctx *cli.Context) error {
 	}
 
 	if version == "" {
+		if err = requireInteractive("the version is required"); err != nil {
+			return err
+		}
 		list := source.List()
 		var arr []string
 		for _, version := range list {
@@ -114,3 +111,15 @@ func useCmd(ctx *cli.Context) error {
 
 	return source.Use(version, scope)
 }
+
+// scopeRecordSources returns the records to be changed when using a version in the scope.
+func scopeRecordSources(scope internal.UseScope) []internal.RecordSource {
+	switch scope {
+	case internal.Global:
+		return []internal.RecordSource{internal.SessionRecordSource, internal.GlobalRecordSource}
+	case internal.Project:
+		return []internal.RecordSource{internal.ProjectRecordSource}
+	default:
+		return []internal.RecordSource{internal.SessionRecordSource}
+	}
+}
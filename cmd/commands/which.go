@@ -0,0 +1,95 @@
+/*
+ *    Copyright 2024 Han Li and contributors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package commands
+
+import (
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/urfave/cli/v2"
+	"github.com/version-fox/vfox/internal"
+)
+
+var Which = &cli.Command{
+	Name:      "which",
+	Usage:     "show the binary of the active sdk versions",
+	UsageText: "vfox which <binary>",
+	Action:    whichCmd,
+}
+
+var Where = &cli.Command{
+	Name:      "where",
+	Usage:     "show the install path of a version of sdk",
+	UsageText: "vfox where <sdk-name>[@<version>]",
+	Action:    whereCmd,
+}
+
+// whichItem is the structured output of `vfox which`.
+type whichItem struct {
+	Path    string `json:"path" yaml:"path"`
+	Sdk     string `json:"sdk" yaml:"sdk"`
+	Version string `json:"version" yaml:"version"`
+}
+
+// whereItem is the structured output of `vfox where`.
+type whereItem struct {
+	Path string `json:"path" yaml:"path"`
+}
+
+func whichCmd(ctx *cli.Context) error {
+	name := ctx.Args().First()
+	if name == "" {
+		return cli.Exit("binary name is required", 1)
+	}
+	manager := internal.NewSdkManager(internal.GlobalRecordSource, internal.SessionRecordSource, internal.ProjectRecordSource)
+	defer manager.Close()
+	bin, sdk, version, err := manager.Which(name)
+	if err != nil {
+		return cli.Exit(err.Error(), 1)
+	}
+	sdkName := strings.ToLower(sdk.Plugin.SdkName)
+	if isStructured() {
+		return printStructured(whichItem{Path: bin, Sdk: sdkName, Version: string(version)})
+	}
+	label := sdkName + "@" + string(version)
+	pterm.Println(bin, pterm.Gray("("+label+")"))
+	return nil
+}
+
+func whereCmd(ctx *cli.Context) error {
+	sdkArg := ctx.Args().First()
+	if sdkArg == "" {
+		return cli.Exit("sdk name is required", 1)
+	}
+	name, version, _ := strings.Cut(sdkArg, "@")
+	name = strings.ToLower(name)
+	manager := internal.NewSdkManager(internal.GlobalRecordSource, internal.SessionRecordSource, internal.ProjectRecordSource)
+	defer manager.Close()
+	source, err := manager.LookupSdk(name)
+	if err != nil {
+		return cli.Exit(name+" not supported, error: "+err.Error(), 1)
+	}
+	path, err := source.Where(internal.Version(version))
+	if err != nil {
+		return cli.Exit(err.Error(), 1)
+	}
+	if isStructured() {
+		return printStructured(whereItem{Path: path})
+	}
+	pterm.Println(path)
+	return nil
+}
@@ -77,6 +77,11 @@ function sidebar(): DefaultTheme.Sidebar {
                         {text: 'http', link: '/plugins/library/http'},
                         {text: 'html', link: '/plugins/library/html'},
                         {text: 'json', link: '/plugins/library/json'},
+                        {text: 'file', link: '/plugins/library/file'},
+                        {text: 'cmd', link: '/plugins/library/cmd'},
+                        {text: 'archiver', link: '/plugins/library/archiver'},
+                        {text: 'semver', link: '/plugins/library/semver'},
+                        {text: 'strings', link: '/plugins/library/strings'},
                     ]
                 },
 
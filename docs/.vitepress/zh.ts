@@ -85,6 +85,11 @@ function sidebar(): DefaultTheme.Sidebar {
                         {text: 'http', link: '/zh-hans/plugins/library/http'},
                         {text: 'html', link: '/zh-hans/plugins/library/html'},
                         {text: 'json', link: '/zh-hans/plugins/library/json'},
+                        {text: 'file', link: '/zh-hans/plugins/library/file'},
+                        {text: 'cmd', link: '/zh-hans/plugins/library/cmd'},
+                        {text: 'archiver', link: '/zh-hans/plugins/library/archiver'},
+                        {text: 'semver', link: '/zh-hans/plugins/library/semver'},
+                        {text: 'strings', link: '/zh-hans/plugins/library/strings'},
                     ]
                 }, 
 
@@ -5,6 +5,7 @@ go 1.21.7
 require (
 	atomicgo.dev/cursor v0.2.0
 	atomicgo.dev/keyboard v0.2.9
+	github.com/ProtonMail/go-crypto v1.1.6
 	github.com/PuerkitoBio/goquery v1.9.1
 	github.com/lithammer/fuzzysearch v1.1.8
 	github.com/pterm/pterm v0.12.79
@@ -20,6 +21,7 @@ require (
 require (
 	atomicgo.dev/schedule v0.1.0 // indirect
 	github.com/andybalholm/cascadia v1.3.2 // indirect
+	github.com/cloudflare/circl v1.3.7 // indirect
 	github.com/containerd/console v1.0.4 // indirect
 	github.com/cpuguy83/go-md2man/v2 v2.0.4 // indirect
 	github.com/gookit/color v1.5.4 // indirect
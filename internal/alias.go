@@ -0,0 +1,155 @@
+/*
+ *    Copyright 2024 Han Li and contributors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/version-fox/vfox/internal/env"
+	"github.com/version-fox/vfox/internal/util"
+	"gopkg.in/yaml.v3"
+)
+
+const aliasDirname = "aliases"
+
+// Aliases are the user-defined version aliases of a sdk, such as `work` for `18.19.0`,
+// which are kept in a file per sdk under the config path.
+type Aliases struct {
+	Versions map[string]string `yaml:"aliases"`
+	path     string
+}
+
+// LoadAliases reads the aliases file, empty aliases are returned if the file does not exist.
+func LoadAliases(path string) (*Aliases, error) {
+	aliases := &Aliases{
+		Versions: make(map[string]string),
+		path:     path,
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return aliases, nil
+		}
+		return nil, err
+	}
+	if err = yaml.Unmarshal(content, aliases); err != nil {
+		return nil, fmt.Errorf("parse %s failed, err: %w", path, err)
+	}
+	if aliases.Versions == nil {
+		aliases.Versions = make(map[string]string)
+	}
+	return aliases, nil
+}
+
+// Names returns the sorted aliases.
+func (a *Aliases) Names() []string {
+	names := make([]string, 0, len(a.Versions))
+	for name := range a.Versions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Set points the alias to the version.
+func (a *Aliases) Set(alias string, version Version) error {
+	if alias == "" || strings.ContainsAny(alias, "@ \t") {
+		return fmt.Errorf("invalid alias: %q", alias)
+	}
+	if alias == env.SystemVersion || strings.EqualFold(alias, util.LatestVersion) {
+		return fmt.Errorf("%s is a reserved version, can not be an alias", alias)
+	}
+	if alias == string(version) {
+		return fmt.Errorf("alias %s can not point to itself", alias)
+	}
+	a.Versions[alias] = string(version)
+	return nil
+}
+
+// Remove deletes the alias, false is returned if it does not exist.
+func (a *Aliases) Remove(alias string) bool {
+	if _, ok := a.Versions[alias]; !ok {
+		return false
+	}
+	delete(a.Versions, alias)
+	return true
+}
+
+func (a *Aliases) Save() error {
+	if len(a.Versions) == 0 {
+		if err := os.Remove(a.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(a.path), 0755); err != nil {
+		return err
+	}
+	content, err := yaml.Marshal(a)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(a.path, content, 0644)
+}
+
+// Aliases returns the user-defined aliases of the sdk.
+func (b *Sdk) Aliases() (*Aliases, error) {
+	if b.aliases == nil {
+		path := filepath.Join(b.sdkManager.PathMeta.ConfigPath, aliasDirname, strings.ToLower(b.Plugin.SdkName)+".yaml")
+		aliases, err := LoadAliases(path)
+		if err != nil {
+			return nil, err
+		}
+		b.aliases = aliases
+	}
+	return b.aliases, nil
+}
+
+// resolveAlias returns the version the user-defined alias points to, or the version itself
+// if it is not an alias.
+func (b *Sdk) resolveAlias(version Version) Version {
+	aliases, err := b.Aliases()
+	if err != nil {
+		return version
+	}
+	if target, ok := aliases.Versions[string(version)]; ok {
+		return Version(target)
+	}
+	return version
+}
+
+// PluginAliases returns the aliases provided by the Available hook of the plugin, such as `lts`,
+// each of which points to the highest version with it.
+func (b *Sdk) PluginAliases() (map[string]Version, error) {
+	available, err := b.Available()
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]Version)
+	for _, pkg := range available {
+		for _, alias := range pkg.Aliases {
+			if current, ok := result[alias]; !ok || util.CompareVersion(string(pkg.Main.Version), string(current)) > 0 {
+				result[alias] = pkg.Main.Version
+			}
+		}
+	}
+	return result, nil
+}
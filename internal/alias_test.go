@@ -0,0 +1,101 @@
+/*
+ *    Copyright 2024 Han Li and contributors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/version-fox/vfox/internal/env"
+)
+
+func TestAliases(t *testing.T) {
+	manager, _ := newTestManager(t)
+	manager.Record = env.NewReadonlyRecord(map[string]string{"nodejs": "work"}, nil)
+	sdk, err := manager.LookupSdk("nodejs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	aliases, err := sdk.Aliases()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, alias := range []string{"", "a b", "x@1", env.SystemVersion, "latest"} {
+		if err = aliases.Set(alias, "20.0.0"); err == nil {
+			t.Errorf("expected an error for alias %q", alias)
+		}
+	}
+	if err = aliases.Set("work", "20"); err != nil {
+		t.Fatal(err)
+	}
+	if err = aliases.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(manager.PathMeta.ConfigPath, aliasDirname, "nodejs.yaml")
+	loaded, err := LoadAliases(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Versions["work"] != "20" {
+		t.Errorf("unexpected aliases: %v", loaded.Versions)
+	}
+	// The alias is resolved before the version constraint
+	if current := sdk.Current(); current != "20.0.0" {
+		t.Errorf("unexpected current version: %s", current)
+	}
+	if version := sdk.resolveLocalVersion("work"); version != "20.0.0" {
+		t.Errorf("unexpected resolved version: %s", version)
+	}
+	if err = aliases.Set("work", "20.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	if err = aliases.Save(); err != nil {
+		t.Fatal(err)
+	}
+	envs, err := manager.EnvKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(envs.Paths) != 1 {
+		t.Errorf("unexpected paths: %v", envs.Paths)
+	}
+
+	if !loaded.Remove("work") || loaded.Remove("work") {
+		t.Error("unexpected result of removing the alias")
+	}
+	if err = loaded.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(path); !os.IsNotExist(err) {
+		t.Error("expected the empty aliases file to be removed")
+	}
+}
+
+func TestResolvePluginAlias(t *testing.T) {
+	candidates := []*Package{
+		{Main: &Info{Version: "22.1.0"}},
+		{Main: &Info{Version: "20.3.0"}, Aliases: []string{"lts", "iron"}},
+		{Main: &Info{Version: "20.0.5"}, Aliases: []string{"lts"}},
+	}
+	for alias, want := range map[Version]Version{"lts": "20.3.0", "IRON": "20.3.0", "stable": ""} {
+		if got := resolveVersion(alias, candidates); got != want {
+			t.Errorf("resolveVersion(%s) = %s, want %s", alias, got, want)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+/*
+ *    Copyright 2024 Han Li and contributors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package internal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/version-fox/vfox/internal/util"
+)
+
+const (
+	downloadCacheDirname   = "downloads"
+	cacheEntryMetaFilename = "meta.json"
+	partialFileSuffix      = ".part"
+)
+
+// DownloadCache keeps the downloaded files under PathMeta.TempPath.
+// The interrupted downloads are resumed from the partial files, and the finished files
+// are reused by url and checksum, so the same file is downloaded only once.
+type DownloadCache struct {
+	path string
+	mu   sync.Mutex
+	// locks of the entries, to prevent downloading the same file concurrently
+	locks map[string]*sync.Mutex
+}
+
+// CacheEntry is a file in the DownloadCache, the directory of an entry contains
+// the file, or the partial file, and its metadata.
+type CacheEntry struct {
+	Url           string `json:"url"`
+	Filename      string `json:"filename"`
+	ChecksumType  string `json:"checksum_type"`
+	ChecksumValue string `json:"checksum_value"`
+	// ETag and LastModified are used to validate the partial and the cached file.
+	ETag         string `json:"etag"`
+	LastModified string `json:"last_modified"`
+	Size         int64  `json:"size"`
+	Completed    bool   `json:"completed"`
+	LastUsed     int64  `json:"last_used"`
+	dir          string
+}
+
+// FilePath returns the path of the finished file.
+func (e *CacheEntry) FilePath() string {
+	return filepath.Join(e.dir, e.Filename)
+}
+
+// IsCompleted reports whether the file has been downloaded completely.
+func (e *CacheEntry) IsCompleted() bool {
+	return e.Completed && util.FileExists(e.FilePath())
+}
+
+func (e *CacheEntry) partialPath() string {
+	return e.FilePath() + partialFileSuffix
+}
+
+// validator returns the value of the If-Range header used to resume the download.
+func (e *CacheEntry) validator() string {
+	if e.ETag != "" {
+		return e.ETag
+	}
+	return e.LastModified
+}
+
+func (e *CacheEntry) save() error {
+	if err := os.MkdirAll(e.dir, 0755); err != nil {
+		return err
+	}
+	content, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(e.dir, cacheEntryMetaFilename), content, 0644)
+}
+
+// touch records the entry has been used just now.
+func (e *CacheEntry) touch() {
+	e.LastUsed = time.Now().Unix()
+	_ = e.save()
+}
+
+func (e *CacheEntry) remove() error {
+	return os.RemoveAll(e.dir)
+}
+
+// Entry returns the cache entry of the url and the checksum, it is created if not exist.
+// The returned unlock function must be called after using the entry.
+func (c *DownloadCache) Entry(u *url.URL, checksum *Checksum) (*CacheEntry, func()) {
+	if checksum == nil {
+		checksum = NoneChecksum
+	}
+	sum := sha256.Sum256([]byte(u.String() + "\n" + checksum.Type + ":" + checksum.Value))
+	key := hex.EncodeToString(sum[:])[:32]
+
+	c.mu.Lock()
+	lock, ok := c.locks[key]
+	if !ok {
+		lock = &sync.Mutex{}
+		c.locks[key] = lock
+	}
+	c.mu.Unlock()
+	lock.Lock()
+
+	dir := filepath.Join(c.path, key)
+	if entry, err := c.load(dir); err == nil {
+		return entry, lock.Unlock
+	}
+	filename := path.Base(u.Path)
+	if filename == "" || filename == "/" || filename == "." {
+		filename = key
+	}
+	return &CacheEntry{
+		Url:           u.String(),
+		Filename:      filename,
+		ChecksumType:  checksum.Type,
+		ChecksumValue: checksum.Value,
+		dir:           dir,
+	}, lock.Unlock
+}
+
+func (c *DownloadCache) load(dir string) (*CacheEntry, error) {
+	content, err := os.ReadFile(filepath.Join(dir, cacheEntryMetaFilename))
+	if err != nil {
+		return nil, err
+	}
+	entry := &CacheEntry{}
+	if err = json.Unmarshal(content, entry); err != nil {
+		return nil, err
+	}
+	entry.dir = dir
+	return entry, nil
+}
+
+// List returns all entries, the most recently used first.
+func (c *DownloadCache) List() ([]*CacheEntry, error) {
+	dir, err := os.ReadDir(c.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var entries []*CacheEntry
+	for _, d := range dir {
+		if !d.IsDir() {
+			continue
+		}
+		entry, err := c.load(filepath.Join(c.path, d.Name()))
+		if err != nil {
+			continue
+		}
+		if !entry.Completed {
+			if info, err := os.Stat(entry.partialPath()); err == nil {
+				entry.Size = info.Size()
+			}
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].LastUsed > entries[j].LastUsed
+	})
+	return entries, nil
+}
+
+// Clean removes all entries.
+func (c *DownloadCache) Clean() error {
+	return os.RemoveAll(c.path)
+}
+
+// Prune removes the entries which have not been used for the duration, and returns them.
+func (c *DownloadCache) Prune(olderThan time.Duration) ([]*CacheEntry, error) {
+	entries, err := c.List()
+	if err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(-olderThan).Unix()
+	var pruned []*CacheEntry
+	for _, entry := range entries {
+		if entry.LastUsed > deadline {
+			continue
+		}
+		if err = entry.remove(); err != nil {
+			return pruned, err
+		}
+		pruned = append(pruned, entry)
+	}
+	return pruned, nil
+}
+
+func newDownloadCache(meta *PathMeta) *DownloadCache {
+	return &DownloadCache{
+		path:  filepath.Join(meta.TempPath, downloadCacheDirname),
+		locks: make(map[string]*sync.Mutex),
+	}
+}
@@ -0,0 +1,104 @@
+/*
+ *    Copyright 2024 Han Li and contributors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package internal
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/version-fox/vfox/internal/config"
+)
+
+func TestDownloadResume(t *testing.T) {
+	content := strings.Repeat("vfox", 1024)
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		w.Header().Set("ETag", `"v1"`)
+		http.ServeContent(w, r, "sdk.tar.gz", time.Now(), strings.NewReader(content))
+	}))
+	defer server.Close()
+
+	meta := &PathMeta{TempPath: t.TempDir()}
+	manager := &Manager{
+		PathMeta:      meta,
+		DownloadCache: newDownloadCache(meta),
+		Config:        &config.Config{Proxy: config.EmptyProxy},
+	}
+	sdk := &Sdk{sdkManager: manager}
+	u, _ := url.Parse(server.URL + "/sdk.tar.gz")
+	sum := sha256.Sum256([]byte(content))
+	checksum := &Checksum{Type: "sha256", Value: hex.EncodeToString(sum[:])}
+
+	// simulate an interrupted download
+	entry, unlock := manager.DownloadCache.Entry(u, checksum)
+	entry.ETag = `"v1"`
+	if err := entry.save(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(entry.partialPath(), []byte(content[:100]), 0644); err != nil {
+		t.Fatal(err)
+	}
+	unlock()
+
+	entry, err := sdk.download(u, checksum, "Downloading...", io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(entry.FilePath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, []byte(content)) {
+		t.Errorf("the resumed file is broken")
+	}
+	if len(ranges) != 1 || ranges[0] != "bytes=100-" {
+		t.Errorf("expected a range request, got %v", ranges)
+	}
+
+	// the finished file is reused
+	if _, err = sdk.download(u, checksum, "Downloading...", io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	if len(ranges) != 1 {
+		t.Errorf("expected the cached file to be reused, got %d requests", len(ranges))
+	}
+
+	entries, err := manager.DownloadCache.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Size != int64(len(content)) {
+		t.Errorf("unexpected cache entries: %v", entries)
+	}
+	pruned, err := manager.DownloadCache.Prune(time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pruned) != 0 {
+		t.Errorf("the recently used file should not be pruned")
+	}
+}
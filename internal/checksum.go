@@ -22,41 +22,150 @@ import (
 	"crypto/sha256"
 	"crypto/sha512"
 	"encoding/hex"
-	"github.com/pterm/pterm"
-	"os"
+	"errors"
+	"fmt"
+	"hash"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/sha3"
 )
 
+var hashFactories = map[string]func() hash.Hash{
+	"md5":         md5.New,
+	"sha1":        sha1.New,
+	"sha256":      sha256.New,
+	"sha384":      sha512.New384,
+	"sha512":      sha512.New,
+	"sha3-256":    sha3.New256,
+	"sha3-384":    sha3.New384,
+	"sha3-512":    sha3.New512,
+	"blake2b-256": func() hash.Hash { h, _ := blake2b.New256(nil); return h },
+	"blake2b-512": func() hash.Hash { h, _ := blake2b.New512(nil); return h },
+}
+
+// checksumTypes is the order of preference of the hash algorithms.
+var checksumTypes = []string{
+	"sha256", "sha512", "sha384", "sha3-256", "sha3-384", "sha3-512",
+	"blake2b-256", "blake2b-512", "sha1", "md5",
+}
+
+// Checksum is the expected digest of a file, Type is the hash algorithm.
+// A plugin may provide several digests of the same file, the rest of them are kept
+// in Additional, and all of them must match.
 type Checksum struct {
-	Value string
-	Type  string
+	Value      string
+	Type       string
+	Additional []*Checksum
+}
+
+// newChecksum creates a checksum from the digests keyed by the hash algorithm, the empty
+// ones are ignored. The preferred digest is displayed to the user, sha256 is preferred.
+func newChecksum(digests map[string]string) *Checksum {
+	var checksum *Checksum
+	for _, typ := range checksumTypes {
+		value := digests[typ]
+		if value == "" {
+			continue
+		}
+		if checksum == nil {
+			checksum = &Checksum{Type: typ, Value: value}
+		} else {
+			checksum.Additional = append(checksum.Additional, &Checksum{Type: typ, Value: value})
+		}
+	}
+	if checksum == nil {
+		return NoneChecksum
+	}
+	return checksum
+}
+
+// Digests returns all digests to be verified.
+func (c *Checksum) Digests() []*Checksum {
+	if c == nil || c.Type == "none" {
+		return nil
+	}
+	return append([]*Checksum{{Value: c.Value, Type: c.Type}}, c.Additional...)
 }
 
-func (c *Checksum) verify(path string) bool {
-	fileData, err := os.ReadFile(path)
+// newHasher returns a writer which computes all digests of the checksum in one pass.
+func (c *Checksum) newHasher() (*checksumHasher, error) {
+	h := &checksumHasher{}
+	for _, digest := range c.Digests() {
+		factory, ok := hashFactories[digest.Type]
+		if !ok {
+			return nil, fmt.Errorf("unsupported checksum type: %s", digest.Type)
+		}
+		h.digests = append(h.digests, digest)
+		h.hashes = append(h.hashes, factory())
+	}
+	return h, nil
+}
+
+// digestFile computes the digest of the file with the hash algorithm.
+func digestFile(path, typ string) (string, error) {
+	factory, ok := hashFactories[typ]
+	if !ok {
+		return "", fmt.Errorf("unsupported checksum type: %s", typ)
+	}
+	h := factory()
+	if err := hashFile(h, path); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// verify computes the digests of the file without loading the whole file into memory.
+func (c *Checksum) verify(path string) error {
+	h, err := c.newHasher()
 	if err != nil {
-		return false
+		return err
+	}
+	if err = hashFile(h, path); err != nil {
+		return err
 	}
-	var hash []byte
-	if c.Type == "sha256" {
-		hashValue := sha256.Sum256(fileData)
-		hash = hashValue[:]
-	} else if c.Type == "sha512" {
-		hashValue := sha512.Sum512(fileData)
-		hash = hashValue[:]
-	} else if c.Type == "sha1" {
-		hashValue := sha1.Sum(fileData)
-		hash = hashValue[:]
-	} else if c.Type == "md5" {
-		hashValue := md5.Sum(fileData)
-		hash = hashValue[:]
-	} else if c.Type == "none" {
-		pterm.Printf("%s: Checksum is not provided, skip verify...\n", pterm.LightYellow("WARNING"))
-		return true
-	} else {
-		return false
+	return h.Verify()
+}
+
+// ChecksumError is returned if a digest of the file does not match the expected one.
+type ChecksumError struct {
+	Type     string
+	Expected string
+	Actual   string
+}
+
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("%s checksum mismatch, expected: %s, actual: %s", e.Type, e.Expected, e.Actual)
+}
+
+// checksumHasher computes several digests of the data written into it.
+type checksumHasher struct {
+	digests []*Checksum
+	hashes  []hash.Hash
+}
+
+func (h *checksumHasher) Write(p []byte) (int, error) {
+	for _, hh := range h.hashes {
+		_, _ = hh.Write(p)
+	}
+	return len(p), nil
+}
+
+// Verify compares the computed digests with the expected ones,
+// a ChecksumError is returned for each mismatched digest.
+func (h *checksumHasher) Verify() error {
+	var errs []error
+	for i, digest := range h.digests {
+		actual := hex.EncodeToString(h.hashes[i].Sum(nil))
+		if !strings.EqualFold(strings.TrimSpace(digest.Value), actual) {
+			errs = append(errs, &ChecksumError{
+				Type:     digest.Type,
+				Expected: digest.Value,
+				Actual:   actual,
+			})
+		}
 	}
-	checksum := hex.EncodeToString(hash)
-	return checksum == c.Value
+	return errors.Join(errs...)
 }
 
 var NoneChecksum = &Checksum{
@@ -0,0 +1,130 @@
+/*
+ *    Copyright 2024 Han Li and contributors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package internal
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/version-fox/vfox/internal/config"
+)
+
+func TestChecksumVerify(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sdk.txt")
+	if err := os.WriteFile(path, []byte("vfox"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		checksum *Checksum
+		mismatch []string
+	}{
+		{
+			name:     "case insensitive",
+			checksum: &Checksum{Type: "sha256", Value: "29158B777949F4DAE1EB3E613DA6146B5AFD266254F922892F88C41963B71119"},
+		},
+		{
+			name: "all digests",
+			checksum: (&LuaCheckSum{
+				Sha256:     "29158b777949f4dae1eb3e613da6146b5afd266254f922892f88c41963b71119",
+				Sha384:     "9e304a800c08afecacf8d7e5516ebe96649a2f2e16e77b6ef1ac552b4528fc2500902039414a7d6f79d4b0a5dba0f479",
+				Sha3_256:   "f21dad21c0261f1cbda40c81652a85d7bed1cee5d940c3f167e724693acf6a19",
+				Blake2b256: "9114a13b8bea409a858f0938f73f0d6a5dd281af833831354a676d2eaa0fbefb",
+			}).Checksum(),
+		},
+		{
+			name: "mismatched digests",
+			checksum: (&LuaCheckSum{
+				Sha256:   "29158b777949f4dae1eb3e613da6146b5afd266254f922892f88c41963b71119",
+				Sha3_256: "xxx",
+				Md5:      "xxx",
+			}).Checksum(),
+			mismatch: []string{"sha3-256", "md5"},
+		},
+		{
+			name:     "none",
+			checksum: NoneChecksum,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.checksum.verify(path)
+			var mismatch []string
+			if err != nil {
+				for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+					var checksumErr *ChecksumError
+					if !errors.As(e, &checksumErr) {
+						t.Fatalf("unexpected error: %v", e)
+					}
+					mismatch = append(mismatch, checksumErr.Type)
+				}
+			}
+			if len(mismatch) != len(tt.mismatch) {
+				t.Fatalf("expected mismatched digests %v, got %v", tt.mismatch, mismatch)
+			}
+			for i := range mismatch {
+				if mismatch[i] != tt.mismatch[i] {
+					t.Errorf("expected mismatched digests %v, got %v", tt.mismatch, mismatch)
+				}
+			}
+		})
+	}
+}
+
+func TestChecksumUnsupportedType(t *testing.T) {
+	checksum := &Checksum{Type: "crc32", Value: "xxx"}
+	if err := checksum.verify(os.DevNull); err == nil {
+		t.Errorf("expected an error for an unsupported checksum type")
+	}
+}
+
+func TestDownloadChecksumMismatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("vfox"))
+	}))
+	defer server.Close()
+
+	meta := &PathMeta{TempPath: t.TempDir()}
+	manager := &Manager{
+		PathMeta:      meta,
+		DownloadCache: newDownloadCache(meta),
+		Config:        &config.Config{Proxy: config.EmptyProxy},
+	}
+	sdk := &Sdk{sdkManager: manager}
+	u, _ := url.Parse(server.URL + "/sdk.tar.gz")
+	checksum := &Checksum{Type: "sha256", Value: "xxx"}
+
+	_, err := sdk.download(u, checksum, "Downloading...", io.Discard)
+	var checksumErr *ChecksumError
+	if !errors.As(err, &checksumErr) {
+		t.Fatalf("expected a checksum error, got %v", err)
+	}
+	entry, unlock := manager.DownloadCache.Entry(u, checksum)
+	defer unlock()
+	if entry.IsCompleted() {
+		t.Errorf("the broken file should not be cached")
+	}
+	if _, err = os.Stat(entry.partialPath()); !os.IsNotExist(err) {
+		t.Errorf("the broken file should be removed")
+	}
+}
@@ -24,16 +24,26 @@ import (
 )
 
 type Config struct {
-	Proxy   *Proxy   `yaml:"proxy"`
-	Storage *Storage `yaml:"storage"`
+	Proxy     *Proxy     `yaml:"proxy"`
+	Storage   *Storage   `yaml:"storage"`
+	Project   *Project   `yaml:"project"`
+	Download  *Download  `yaml:"download"`
+	Signature *Signature `yaml:"signature"`
+
+	LegacyVersionFile *LegacyVersionFile `yaml:"legacyVersionFile"`
 }
 
 const filename = "config.yaml"
 
 var (
 	defaultConfig = &Config{
-		Proxy:   EmptyProxy,
-		Storage: EmptyStorage,
+		Proxy:     EmptyProxy,
+		Storage:   EmptyStorage,
+		Project:   EmptyProject,
+		Download:  EmptyDownload,
+		Signature: EmptySignature,
+
+		LegacyVersionFile: EmptyLegacyVersionFile,
 	}
 )
 
@@ -60,6 +70,18 @@ func NewConfigWithPath(p string) (*Config, error) {
 	if config.Storage == nil {
 		config.Storage = EmptyStorage
 	}
+	if config.Project == nil {
+		config.Project = EmptyProject
+	}
+	if config.Download == nil {
+		config.Download = EmptyDownload
+	}
+	if config.Signature == nil {
+		config.Signature = EmptySignature
+	}
+	if config.LegacyVersionFile == nil {
+		config.LegacyVersionFile = EmptyLegacyVersionFile
+	}
 	return config, nil
 
 }
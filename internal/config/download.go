@@ -0,0 +1,36 @@
+/*
+ *    Copyright 2024 Han Li and contributors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package config
+
+const defaultConcurrency = 4
+
+type Download struct {
+	// Concurrency is the maximum number of files downloaded at the same time.
+	Concurrency int `yaml:"concurrency"`
+}
+
+var EmptyDownload = &Download{
+	Concurrency: defaultConcurrency,
+}
+
+// Workers returns the number of download workers, at least one.
+func (d *Download) Workers() int {
+	if d.Concurrency <= 0 {
+		return defaultConcurrency
+	}
+	return d.Concurrency
+}
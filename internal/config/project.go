@@ -0,0 +1,36 @@
+/*
+ *    Copyright 2024 Han Li and contributors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package config
+
+// Project is the configuration of the project records (.tool-versions).
+type Project struct {
+	// StopMarker stops searching .tool-versions in parent directories
+	// at the first directory containing it, such as .git.
+	// Empty means searching up to the filesystem root.
+	StopMarker string `yaml:"stopMarker"`
+	// Lockfile creates .tool-versions.lock in the project after installing,
+	// an existing lock file is always updated.
+	Lockfile bool `yaml:"lockfile"`
+	// Roots are the directories searched for .tool-versions by `vfox prune`,
+	// the versions recorded in them are never pruned.
+	Roots []string `yaml:"roots"`
+}
+
+var EmptyProject = &Project{
+	StopMarker: "",
+	Lockfile:   false,
+}
@@ -0,0 +1,37 @@
+/*
+ *    Copyright 2024 Han Li and contributors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package config
+
+import "strings"
+
+// Signature is the policy of verifying the signatures of sdk files.
+type Signature struct {
+	// Required lists the plugins whose sdk files must be signed, "*" means all plugins.
+	Required []string `yaml:"required"`
+}
+
+var EmptySignature = &Signature{}
+
+// IsRequired reports whether the sdk files of the plugin must be signed.
+func (s *Signature) IsRequired(name string) bool {
+	for _, n := range s.Required {
+		if n == "*" || strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
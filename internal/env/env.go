@@ -18,6 +18,9 @@ package env
 
 import (
 	"io"
+	"os"
+	"runtime"
+	"strings"
 )
 
 type Manager interface {
@@ -40,3 +43,42 @@ type Envs struct {
 	Variables Vars
 	Paths     Paths
 }
+
+// Environ applies the variables and prepends the paths to PATH of the base environment,
+// which is in the form of os.Environ, and returns the environment for a child process.
+func (e *Envs) Environ(base []string) []string {
+	// environment variable names are case-insensitive on Windows, such as Path
+	normalize := func(key string) string {
+		if runtime.GOOS == "windows" {
+			return strings.ToUpper(key)
+		}
+		return key
+	}
+	overrides := make(map[string]*string)
+	for k, v := range e.Variables {
+		overrides[normalize(k)] = v
+	}
+	var environ []string
+	path := ""
+	for _, kv := range base {
+		key, value, _ := strings.Cut(kv, "=")
+		if normalize(key) == normalize("PATH") {
+			path = value
+			continue
+		}
+		if _, ok := overrides[normalize(key)]; ok {
+			continue
+		}
+		environ = append(environ, kv)
+	}
+	for k, v := range e.Variables {
+		if v != nil && normalize(k) != normalize("PATH") {
+			environ = append(environ, k+"="+*v)
+		}
+	}
+	paths := append([]string{}, e.Paths...)
+	if path != "" {
+		paths = append(paths, path)
+	}
+	return append(environ, "PATH="+strings.Join(paths, string(os.PathListSeparator)))
+}
@@ -0,0 +1,42 @@
+/*
+ *    Copyright 2024 Han Li and contributors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package env
+
+import (
+	"os"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestEnvsEnviron(t *testing.T) {
+	home := "/opt/java"
+	envs := &Envs{
+		Variables: Vars{"JAVA_HOME": &home, "GOROOT": nil},
+		Paths:     Paths{"/opt/java/bin", "/opt/node/bin"},
+	}
+	environ := envs.Environ([]string{"PATH=/usr/bin", "JAVA_HOME=/old", "GOROOT=/usr/local/go", "HOME=/root"})
+	sort.Strings(environ)
+	want := []string{
+		"HOME=/root",
+		"JAVA_HOME=/opt/java",
+		"PATH=/opt/java/bin" + string(os.PathListSeparator) + "/opt/node/bin" + string(os.PathListSeparator) + "/usr/bin",
+	}
+	if !reflect.DeepEqual(environ, want) {
+		t.Errorf("unexpected environ: %v", environ)
+	}
+}
@@ -17,11 +17,9 @@
 package env
 
 import (
-	"bufio"
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
 
 	"github.com/version-fox/vfox/internal/util"
 )
@@ -32,11 +30,23 @@ func IsRecordExist(dirPath string) bool {
 	return util.FileExists(filepath.Join(dirPath, filename))
 }
 
+// IsRecordFile reports whether the path is a .tool-versions, rather than a legacy version file.
+func IsRecordFile(path string) bool {
+	return filepath.Base(path) == filename
+}
+
 // Record is an interface to record tool version
 type Record interface {
 	Add(name, version string)
 	Remove(name string)
+	// Export returns the preferred version of each sdk.
 	Export() map[string]string
+	// Versions returns the versions of the named sdk in order of preference,
+	// the first installed one is used.
+	Versions(name string) []string
+	// Origin returns the path of the file the version of the named sdk is read from,
+	// or an empty string if the sdk is not recorded.
+	Origin(name string) string
 	Save() error
 }
 type empty struct {
@@ -52,6 +62,14 @@ func (e empty) Export() map[string]string {
 func (e empty) Remove(name string) {
 }
 
+func (e empty) Versions(name string) []string {
+	return nil
+}
+
+func (e empty) Origin(name string) string {
+	return ""
+}
+
 func (e empty) Save() error {
 	return nil
 }
@@ -59,21 +77,36 @@ func (e empty) Save() error {
 var EmptyRecord = &empty{}
 
 type single struct {
-	// Sdks sdkName -> version
-	Sdks map[string]string
-	path string
+	versions *ToolVersions
+	path     string
 }
 
 func (t *single) Remove(name string) {
-	delete(t.Sdks, name)
+	t.versions.Delete(name)
 }
 
+// Export returns the preferred version of each sdk, see Versions for the fallback versions.
 func (t *single) Export() map[string]string {
-	return t.Sdks
+	result := make(map[string]string)
+	for _, name := range t.versions.Names() {
+		result[name] = t.versions.Get(name)[0]
+	}
+	return result
+}
+
+func (t *single) Versions(name string) []string {
+	return t.versions.Get(name)
+}
+
+func (t *single) Origin(name string) string {
+	if len(t.versions.Get(name)) > 0 {
+		return t.path
+	}
+	return ""
 }
 
 func (t *single) Save() error {
-	if len(t.Sdks) == 0 {
+	if len(t.versions.lines) == 0 && !util.FileExists(t.path) {
 		return nil
 	}
 	file, err := os.Create(t.path)
@@ -81,51 +114,35 @@ func (t *single) Save() error {
 		return err
 	}
 	defer file.Close()
-
-	for k, v := range t.Sdks {
-		_, err := fmt.Fprintf(file, "%s %s\n", k, v)
-		if err != nil {
-			return err
-		}
-	}
-	return nil
+	_, err = t.versions.WriteTo(file)
+	return err
 }
 
 func (t *single) String() string {
 	return filename
 }
 
+// Add records the version of the sdk, the fallback versions are replaced.
 func (t *single) Add(name, version string) {
-	t.Sdks[name] = version
-	return
+	t.versions.Set(name, version)
 }
 
 func newSingle(dirPath string) (Record, error) {
-	file := filepath.Join(dirPath, filename)
-	versionsMap := make(map[string]string)
-	if util.FileExists(file) {
-		file, err := os.Open(file)
+	path := filepath.Join(dirPath, filename)
+	versions := &ToolVersions{newline: "\n"}
+	if util.FileExists(path) {
+		file, err := os.Open(path)
 		if err != nil {
 			return nil, err
 		}
 		defer file.Close()
-
-		scanner := bufio.NewScanner(file)
-		for scanner.Scan() {
-			line := scanner.Text()
-			parts := strings.Split(line, " ")
-			if len(parts) == 2 {
-				versionsMap[parts[0]] = parts[1]
-			}
-		}
-
-		if err := scanner.Err(); err != nil {
+		if versions, err = ParseToolVersions(file); err != nil {
 			return nil, err
 		}
 	}
 	return &single{
-		Sdks: versionsMap,
-		path: file,
+		versions: versions,
+		path:     path,
 	}, nil
 }
 
@@ -154,6 +171,26 @@ func (m *multi) Export() map[string]string {
 	return result
 }
 
+func (m *multi) Versions(name string) []string {
+	// The later records override the earlier ones, see Export.
+	for i := len(m.slave) - 1; i >= 0; i-- {
+		if versions := m.slave[i].Versions(name); len(versions) > 0 {
+			return versions
+		}
+	}
+	return m.main.Versions(name)
+}
+
+func (m *multi) Origin(name string) string {
+	// The later records override the earlier ones, see Export.
+	for i := len(m.slave) - 1; i >= 0; i-- {
+		if origin := m.slave[i].Origin(name); origin != "" {
+			return origin
+		}
+	}
+	return m.main.Origin(name)
+}
+
 func (m *multi) Add(name, version string) {
 	m.main.Add(name, version)
 	for _, record := range m.slave {
@@ -175,6 +212,146 @@ func (m *multi) Save() error {
 	return nil
 }
 
+// readonly is a record which can not be changed by vfox,
+// such as the versions read from the version files of other tools.
+type readonly struct {
+	versions map[string]string
+	origins  map[string]string
+}
+
+func (r *readonly) Add(name, version string) {
+}
+
+func (r *readonly) Remove(name string) {
+}
+
+func (r *readonly) Export() map[string]string {
+	return r.versions
+}
+
+func (r *readonly) Versions(name string) []string {
+	if version, ok := r.versions[name]; ok {
+		return []string{version}
+	}
+	return nil
+}
+
+func (r *readonly) Origin(name string) string {
+	return r.origins[name]
+}
+
+func (r *readonly) Save() error {
+	return nil
+}
+
+// NewReadonlyRecord returns a record of the versions which are read from the origins.
+func NewReadonlyRecord(versions, origins map[string]string) Record {
+	return &readonly{
+		versions: versions,
+		origins:  origins,
+	}
+}
+
+// project is a record of the working directory, which also inherits the records
+// found in its parent directories. Only the record of the working directory is writable,
+// the parent records are read-only, and the nearest record wins.
+type project struct {
+	main    Record
+	parents []Record
+}
+
+func (p *project) Add(name, version string) {
+	p.main.Add(name, version)
+}
+
+func (p *project) Remove(name string) {
+	p.main.Remove(name)
+}
+
+func (p *project) Export() map[string]string {
+	result := make(map[string]string)
+	// parents are sorted from nearest to farthest
+	for i := len(p.parents) - 1; i >= 0; i-- {
+		for k, v := range p.parents[i].Export() {
+			result[k] = v
+		}
+	}
+	for k, v := range p.main.Export() {
+		result[k] = v
+	}
+	return result
+}
+
+func (p *project) Versions(name string) []string {
+	if versions := p.main.Versions(name); len(versions) > 0 {
+		return versions
+	}
+	for _, parent := range p.parents {
+		if versions := parent.Versions(name); len(versions) > 0 {
+			return versions
+		}
+	}
+	return nil
+}
+
+func (p *project) Origin(name string) string {
+	if origin := p.main.Origin(name); origin != "" {
+		return origin
+	}
+	for _, parent := range p.parents {
+		if origin := parent.Origin(name); origin != "" {
+			return origin
+		}
+	}
+	return ""
+}
+
+func (p *project) Save() error {
+	return p.main.Save()
+}
+
+// NewProjectRecord returns the record of dirPath merged with the records of its parent directories.
+// The search goes up to the filesystem root, or stops at the first directory that contains
+// stopMarker (such as .git) if it is not empty.
+func NewProjectRecord(dirPath, stopMarker string) (Record, error) {
+	main, err := newSingle(dirPath)
+	if err != nil {
+		return nil, fmt.Errorf("read version record failed, error: %w", err)
+	}
+	record := &project{main: main}
+	dir := dirPath
+	for {
+		if stopMarker != "" && util.FileExists(filepath.Join(dir, stopMarker)) {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+		if !IsRecordExist(dir) {
+			continue
+		}
+		r, err := newSingle(dir)
+		if err != nil {
+			return nil, fmt.Errorf("read version record failed, error: %w", err)
+		}
+		record.parents = append(record.parents, r)
+	}
+	return record, nil
+}
+
+// NewMultiRecord combines several records into one, the later records override the earlier ones.
+func NewMultiRecord(main Record, slave ...Record) Record {
+	if len(slave) == 0 {
+		return main
+	}
+	return &multi{
+		main:  main,
+		slave: slave,
+	}
+}
+
 func NewRecord(mainPath string, salve ...string) (Record, error) {
 	main, err := newSingle(mainPath)
 	if err != nil {
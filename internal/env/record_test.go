@@ -0,0 +1,96 @@
+/*
+ *    Copyright 2024 Han Li and contributors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeRecord(t *testing.T, dir, content string) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, filename), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNewProjectRecord(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "services", "api")
+	writeRecord(t, root, "nodejs 18.0.0\njava 17\n")
+	writeRecord(t, sub, "nodejs 20.1.0\n")
+
+	record, err := NewProjectRecord(sub, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	versions := record.Export()
+	if versions["nodejs"] != "20.1.0" {
+		t.Errorf("expected nodejs 20.1.0, got %s", versions["nodejs"])
+	}
+	if versions["java"] != "17" {
+		t.Errorf("expected java 17, got %s", versions["java"])
+	}
+	if origin := record.Origin("java"); origin != filepath.Join(root, filename) {
+		t.Errorf("unexpected origin of java: %s", origin)
+	}
+	if origin := record.Origin("nodejs"); origin != filepath.Join(sub, filename) {
+		t.Errorf("unexpected origin of nodejs: %s", origin)
+	}
+
+	// Only the record of the working directory is written.
+	record.Add("python", "3.12.0")
+	if err = record.Save(); err != nil {
+		t.Fatal(err)
+	}
+	parent, err := newSingle(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := parent.Export()["python"]; ok {
+		t.Errorf("parent record should not be modified")
+	}
+}
+
+func TestNewProjectRecordWithStopMarker(t *testing.T) {
+	root := t.TempDir()
+	repo := filepath.Join(root, "repo")
+	sub := filepath.Join(repo, "services")
+	writeRecord(t, root, "java 17\n")
+	writeRecord(t, repo, "nodejs 18.0.0\n")
+	if err := os.Mkdir(filepath.Join(repo, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	record, err := NewProjectRecord(sub, ".git")
+	if err != nil {
+		t.Fatal(err)
+	}
+	versions := record.Export()
+	if versions["nodejs"] != "18.0.0" {
+		t.Errorf("expected nodejs 18.0.0, got %s", versions["nodejs"])
+	}
+	if _, ok := versions["java"]; ok {
+		t.Errorf("the search should stop at the directory containing the marker")
+	}
+}
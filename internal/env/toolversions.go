@@ -0,0 +1,195 @@
+/*
+ *    Copyright 2024 Han Li and contributors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package env
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+const (
+	// SystemVersion means the version installed outside vfox is used.
+	SystemVersion = "system"
+	// RefVersionPrefix is the prefix of a version built from a git ref, such as ref:v1.0.0.
+	RefVersionPrefix = "ref:"
+	// PathVersionPrefix is the prefix of a version installed in a custom directory, such as path:/opt/node.
+	PathVersionPrefix = "path:"
+)
+
+// PathVersion returns the directory of a version installed in a custom directory, see PathVersionPrefix.
+func PathVersion(version string) (string, bool) {
+	dir, ok := strings.CutPrefix(version, PathVersionPrefix)
+	return dir, ok && dir != ""
+}
+
+// ToolVersions is the content of a .tool-versions file in the asdf syntax:
+//
+//	# comment
+//	nodejs 20.1.0 18.0.0 # fallback versions
+//	python	system
+//
+// Each line is a tool name followed by one or more versions in order of preference,
+// separated by spaces or tabs. Comments, blank lines and the order of the lines are
+// kept when the file is written back, and the unchanged lines are written as is.
+type ToolVersions struct {
+	lines []*toolLine
+	// newline is the line ending of the original file
+	newline string
+}
+
+type toolLine struct {
+	// raw is the original text of the line, empty if the line is changed
+	raw string
+	// name is empty for comments and blank lines
+	name     string
+	versions []string
+	// separator is the whitespace between the fields, a space or a tab
+	separator string
+	// comment is the trailing comment including the leading whitespace
+	comment string
+}
+
+func (l *toolLine) String() string {
+	if l.raw != "" || l.name == "" {
+		return l.raw
+	}
+	return l.name + l.separator + strings.Join(l.versions, l.separator) + l.comment
+}
+
+// ParseToolVersions parses the content of a .tool-versions file.
+func ParseToolVersions(r io.Reader) (*ToolVersions, error) {
+	t := &ToolVersions{newline: "\n"}
+	reader := bufio.NewReader(r)
+	for {
+		text, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if text == "" && err == io.EOF {
+			break
+		}
+		if strings.HasSuffix(text, "\r\n") {
+			t.newline = "\r\n"
+		}
+		t.lines = append(t.lines, parseToolLine(strings.TrimRight(text, "\r\n")))
+		if err == io.EOF {
+			break
+		}
+	}
+	return t, nil
+}
+
+func parseToolLine(text string) *toolLine {
+	line := &toolLine{raw: text, separator: " "}
+	content := text
+	// A comment starts with # at the beginning of the line or after a whitespace.
+	for i, c := range text {
+		if c == '#' && (i == 0 || text[i-1] == ' ' || text[i-1] == '\t') {
+			content = text[:i]
+			line.comment = text[len(strings.TrimRight(content, " \t")):]
+			content = strings.TrimRight(content, " \t")
+			break
+		}
+	}
+	fields := strings.Fields(content)
+	if len(fields) == 0 {
+		return line
+	}
+	line.name = fields[0]
+	line.versions = fields[1:]
+	if i := strings.IndexAny(content, " \t"); i >= 0 && content[i] == '\t' {
+		line.separator = "\t"
+	}
+	return line
+}
+
+// Names returns the names of the tools in the order of the file.
+func (t *ToolVersions) Names() []string {
+	var names []string
+	seen := make(map[string]struct{})
+	for _, line := range t.lines {
+		if line.name == "" || len(line.versions) == 0 {
+			continue
+		}
+		if _, ok := seen[line.name]; ok {
+			continue
+		}
+		seen[line.name] = struct{}{}
+		names = append(names, line.name)
+	}
+	return names
+}
+
+// Get returns the versions of the tool in order of preference, the first line wins
+// if the tool is listed more than once.
+func (t *ToolVersions) Get(name string) []string {
+	if line := t.find(name); line != nil {
+		return line.versions
+	}
+	return nil
+}
+
+// Set replaces the versions of the tool, keeping its position and its comment.
+// The tool is appended to the end of the file if it is not listed yet.
+func (t *ToolVersions) Set(name string, versions ...string) {
+	if line := t.find(name); line != nil {
+		line.versions = versions
+		line.raw = ""
+		return
+	}
+	t.lines = append(t.lines, &toolLine{name: name, versions: versions, separator: " "})
+}
+
+// Delete removes all lines of the tool.
+func (t *ToolVersions) Delete(name string) {
+	lines := t.lines[:0]
+	for _, line := range t.lines {
+		if line.name != name {
+			lines = append(lines, line)
+		}
+	}
+	t.lines = lines
+}
+
+func (t *ToolVersions) find(name string) *toolLine {
+	for _, line := range t.lines {
+		if line.name == name && len(line.versions) > 0 {
+			return line
+		}
+	}
+	return nil
+}
+
+// WriteTo writes the content in the asdf syntax.
+func (t *ToolVersions) WriteTo(w io.Writer) (int64, error) {
+	var total int64
+	for _, line := range t.lines {
+		n, err := io.WriteString(w, line.String()+t.newline)
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+func (t *ToolVersions) String() string {
+	var builder strings.Builder
+	_, _ = t.WriteTo(&builder)
+	return builder.String()
+}
@@ -0,0 +1,118 @@
+/*
+ *    Copyright 2024 Han Li and contributors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const toolVersions = `# managed by the team
+nodejs 20.1.0 18.0.0 # fallback to 18
+python	3.11.4	system
+
+ruby ref:v3.3.0
+java path:/opt/java#21
+golang
+`
+
+func TestParseToolVersions(t *testing.T) {
+	versions, err := ParseToolVersions(strings.NewReader(toolVersions))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := versions.Names(); !reflect.DeepEqual(names, []string{"nodejs", "python", "ruby", "java"}) {
+		t.Errorf("unexpected names: %v", names)
+	}
+	tests := map[string][]string{
+		"nodejs": {"20.1.0", "18.0.0"},
+		"python": {"3.11.4", SystemVersion},
+		"ruby":   {"ref:v3.3.0"},
+		"java":   {"path:/opt/java#21"},
+		"golang": nil,
+	}
+	for name, want := range tests {
+		if got := versions.Get(name); !reflect.DeepEqual(got, want) {
+			t.Errorf("Get(%s) = %v, want %v", name, got, want)
+		}
+	}
+	if got := versions.String(); got != toolVersions {
+		t.Errorf("the unchanged content should be written as is, got:\n%s", got)
+	}
+}
+
+func TestToolVersionsWrite(t *testing.T) {
+	versions, err := ParseToolVersions(strings.NewReader(toolVersions))
+	if err != nil {
+		t.Fatal(err)
+	}
+	versions.Set("nodejs", "21.0.0", "20.1.0")
+	versions.Set("python", "3.12.0")
+	versions.Delete("ruby")
+	versions.Set("deno", "1.40.0")
+	want := `# managed by the team
+nodejs 21.0.0 20.1.0 # fallback to 18
+python	3.12.0
+
+java path:/opt/java#21
+golang
+deno 1.40.0
+`
+	if got := versions.String(); got != want {
+		t.Errorf("unexpected content:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestToolVersionsCRLF(t *testing.T) {
+	versions, err := ParseToolVersions(strings.NewReader("nodejs 20.1.0\r\n# comment\r\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	versions.Set("java", "21")
+	if got := versions.String(); got != "nodejs 20.1.0\r\n# comment\r\njava 21\r\n" {
+		t.Errorf("the line ending should be kept, got %q", got)
+	}
+}
+
+func TestRecordKeepsComments(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, filename), []byte(toolVersions), 0644); err != nil {
+		t.Fatal(err)
+	}
+	record, err := newSingle(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := record.Export()["nodejs"]; got != "20.1.0" {
+		t.Errorf("expected the preferred version, got %s", got)
+	}
+	record.Add("ruby", "3.3.0")
+	if err = record.Save(); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(filepath.Join(dir, filename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Replace(toolVersions, "ruby ref:v3.3.0", "ruby 3.3.0", 1)
+	if string(content) != want {
+		t.Errorf("unexpected content:\n%s", content)
+	}
+}
@@ -0,0 +1,106 @@
+/*
+ *    Copyright 2024 Han Li and contributors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/version-fox/vfox/internal/config"
+	"github.com/version-fox/vfox/internal/env"
+)
+
+// testPlugin is a nodejs plugin whose binaries are in the bin directory of the install path.
+const testPlugin = `
+PLUGIN = { name = "nodejs", version = "0.0.1" }
+function PLUGIN:Available(ctx)
+    return {
+        { version = "23.0.0-rc.1" }, { version = "22.1.0" }, { version = "21.0.0" },
+        { version = "20.3.0" }, { version = "20.0.5" }, { version = "20.0.1" }, { version = "19.9.0" },
+    }
+end
+function PLUGIN:PreInstall(ctx) return {} end
+function PLUGIN:EnvKeys(ctx)
+    return { { key = "PATH", value = ctx.path .. "/bin" } }
+end
+`
+
+// newTestManager creates a manager whose paths are in a temporary directory, with the nodejs plugin
+// whose version 20.0.0 is installed and active. The bin directory of the version is returned.
+func newTestManager(t *testing.T) (*Manager, string) {
+	dir := t.TempDir()
+	manager := &Manager{
+		PathMeta: &PathMeta{
+			ConfigPath:     filepath.Join(dir, "config"),
+			TempPath:       filepath.Join(dir, "temp"),
+			PluginPath:     filepath.Join(dir, "plugin"),
+			SdkCachePath:   filepath.Join(dir, "cache"),
+			ShimsPath:      filepath.Join(dir, "shims"),
+			ExecutablePath: "/usr/local/bin/vfox",
+		},
+		Config: &config.Config{
+			Proxy:   config.EmptyProxy,
+			Project: config.EmptyProject,
+		},
+		Record:   env.NewReadonlyRecord(map[string]string{"nodejs": "20.0.0"}, nil),
+		openSdks: make(map[string]*Sdk),
+	}
+	t.Cleanup(func() {
+		for _, sdk := range manager.openSdks {
+			sdk.Close()
+		}
+	})
+
+	pluginDir := filepath.Join(manager.PathMeta.PluginPath, "nodejs")
+	binDir := filepath.Join(installTestVersions(t, manager, "20.0.0")[0], "bin")
+	for _, path := range []string{manager.PathMeta.ConfigPath, pluginDir, binDir} {
+		if err := os.MkdirAll(path, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files := map[string]os.FileMode{
+		filepath.Join(pluginDir, "main.lua"): 0644,
+		filepath.Join(binDir, "node"):        0755,
+		filepath.Join(binDir, "npm"):         0755,
+		filepath.Join(binDir, "README"):      0644,
+	}
+	for path, mode := range files {
+		content := "#!/bin/sh\n"
+		if strings.HasSuffix(path, ".lua") {
+			content = testPlugin
+		}
+		if err := os.WriteFile(path, []byte(content), mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return manager, binDir
+}
+
+// installTestVersions creates the install paths of the nodejs versions and returns them.
+func installTestVersions(t *testing.T, manager *Manager, versions ...string) []string {
+	paths := make([]string, 0, len(versions))
+	for _, version := range versions {
+		path := filepath.Join(manager.PathMeta.SdkCachePath, "nodejs", "v-"+version, "nodejs-"+version)
+		if err := os.MkdirAll(path, 0755); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
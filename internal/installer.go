@@ -0,0 +1,413 @@
+/*
+ *    Copyright 2024 Han Li and contributors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package internal
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/pterm/pterm"
+	"github.com/version-fox/vfox/internal/printer"
+	"github.com/version-fox/vfox/internal/util"
+)
+
+// installLogDirname is the directory of the install logs under PathMeta.TempPath.
+const installLogDirname = "logs"
+
+// installPlan is a prepared installation of a sdk version.
+//
+// The lua state of a plugin is not thread-safe, so the hooks of the plugin are only called
+// while preparing and finishing the plan, one plan after another. Only the downloads,
+// which do not touch the plugin, run concurrently in between.
+type installPlan struct {
+	sdk   *Sdk
+	label string
+	// the directory of the version to be installed
+	rootPath  string
+	pkg       *Package
+	downloads []*downloadTask
+	// frozen means the plan is prepared from the project lock
+	frozen bool
+}
+
+// infos returns the main sdk followed by the additions.
+func (p *installPlan) infos() []*Info {
+	return append([]*Info{p.pkg.Main}, p.pkg.Additions...)
+}
+
+func (p *installPlan) downloadTask(info *Info) *downloadTask {
+	for _, task := range p.downloads {
+		if task.info == info {
+			return task
+		}
+	}
+	return nil
+}
+
+// finish unpacks the downloaded files in order and calls the PostInstall hook.
+// The hook is interrupted once ctx is canceled.
+func (p *installPlan) finish(ctx context.Context) error {
+	mainSdk := p.pkg.Main
+	var installedSdkInfos []*Info
+	path, err := p.sdk.preInstallSdk(mainSdk, p.rootPath, p.downloadTask(mainSdk))
+	if err != nil {
+		return err
+	}
+	installedSdkInfos = append(installedSdkInfos, &Info{
+		Name:    mainSdk.Name,
+		Version: mainSdk.Version,
+		Note:    mainSdk.Note,
+		Path:    path,
+	})
+	if len(p.pkg.Additions) > 0 {
+		pterm.Printf("There are %d additional files that need to be installed...\n", len(p.pkg.Additions))
+		for _, oSdk := range p.pkg.Additions {
+			path, err = p.sdk.preInstallSdk(oSdk, p.rootPath, p.downloadTask(oSdk))
+			if err != nil {
+				return err
+			}
+			installedSdkInfos = append(installedSdkInfos, &Info{
+				Name:    oSdk.Name,
+				Version: oSdk.Version,
+				Path:    path,
+			})
+		}
+	}
+	if err = p.postInstall(ctx, installedSdkInfos); err != nil {
+		return err
+	}
+	pterm.Printf("Install %s success! \n", pterm.LightGreen(p.label))
+	pterm.Printf("Please use %s to use it.\n", pterm.LightBlue(fmt.Sprintf("vfox use %s", p.label)))
+	return nil
+}
+
+// postInstall calls the PostInstall hook, the commands run by the hook through the cmd module
+// are recorded into the install log, which is removed if no command has been run.
+func (p *installPlan) postInstall(ctx context.Context, infos []*Info) error {
+	logPath := p.sdk.installLogPath(p.pkg.Main.Version)
+	var log io.Writer
+	if err := os.MkdirAll(filepath.Dir(logPath), 0755); err == nil {
+		if f, err := os.Create(logPath); err == nil {
+			log = f
+			defer func() {
+				_ = f.Close()
+				if info, err := os.Stat(logPath); err == nil && info.Size() == 0 {
+					_ = os.Remove(logPath)
+				}
+			}()
+		}
+	}
+	unbind := p.sdk.Plugin.bindInstall(ctx, log)
+	defer unbind()
+	if err := p.sdk.Plugin.PostInstall(p.rootPath, infos); err != nil {
+		if info, statErr := os.Stat(logPath); statErr == nil && info.Size() > 0 {
+			return fmt.Errorf("plugin [PostInstall] method error, see the log at %s: %w", logPath, err)
+		}
+		return fmt.Errorf("plugin [PostInstall] method error: %w", err)
+	}
+	return nil
+}
+
+// installLogPath returns the log of the commands run while installing the version.
+func (b *Sdk) installLogPath(version Version) string {
+	name := fmt.Sprintf("%s-%s.log", strings.ToLower(b.Plugin.SdkName), version)
+	return filepath.Join(b.sdkManager.PathMeta.TempPath, installLogDirname, name)
+}
+
+// downloadTask is a remote file of an installPlan.
+type downloadTask struct {
+	sdk   *Sdk
+	info  *Info
+	url   *url.URL
+	label string
+	// the downloaded file, which is kept in the DownloadCache
+	entry *CacheEntry
+	path  string
+	err   error
+}
+
+func isRemotePath(path string) bool {
+	return strings.HasPrefix(path, "https://") || strings.HasPrefix(path, "http://")
+}
+
+// InstallAll installs several sdks at once. The plugins are called one by one,
+// while the files of all sdks are downloaded concurrently.
+// The returned errors are in the same order as args, ErrAlreadyInstalled is returned
+// for the versions which have been installed already.
+func (m *Manager) InstallAll(args []Arg) []error {
+	return m.installAll(args, false)
+}
+
+// InstallMissing is like InstallAll, but a fuzzy version, such as `20`, is satisfied by a matching installed
+// version without looking up the available versions of the plugin.
+func (m *Manager) InstallMissing(args []Arg) []error {
+	return m.installAll(args, true)
+}
+
+func (m *Manager) installAll(args []Arg, preferLocal bool) []error {
+	errs := make([]error, len(args))
+	var plans []*installPlan
+	var indexes []int
+	for i, arg := range args {
+		sdk, err := m.LookupSdk(arg.Name)
+		if err != nil {
+			errs[i] = fmt.Errorf("%s not supported, error: %w", arg.Name, err)
+			continue
+		}
+		if preferLocal {
+			if local := sdk.resolveLocalVersion(Version(arg.Version)); sdk.checkExists(local) {
+				errs[i] = fmt.Errorf("%s is %w", sdk.label(local), ErrAlreadyInstalled)
+				continue
+			}
+		}
+		plan, err := sdk.prepareInstall(Version(arg.Version))
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		plans = append(plans, plan)
+		indexes = append(indexes, i)
+	}
+	for i, err := range m.install(plans) {
+		errs[indexes[i]] = err
+	}
+	return errs
+}
+
+// InstallFrozen installs the sdks exactly as locked in the project lock file,
+// it fails if a version is not locked or a file does not match the locked checksums.
+func (m *Manager) InstallFrozen(args []Arg) []error {
+	errs := make([]error, len(args))
+	lockPath := m.projectLockPath(false)
+	if lockPath == "" {
+		for i := range errs {
+			errs[i] = fmt.Errorf("%s not found, can not install in frozen mode", projectLockFilename)
+		}
+		return errs
+	}
+	lock, err := LoadProjectLock(lockPath)
+	if err != nil {
+		for i := range errs {
+			errs[i] = err
+		}
+		return errs
+	}
+	var plans []*installPlan
+	var indexes []int
+	for i, arg := range args {
+		sdk, err := m.LookupSdk(arg.Name)
+		if err != nil {
+			errs[i] = fmt.Errorf("%s not supported, error: %w", arg.Name, err)
+			continue
+		}
+		plan, err := sdk.prepareFrozenInstall(Version(arg.Version), lock)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		plans = append(plans, plan)
+		indexes = append(indexes, i)
+	}
+	for i, err := range m.install(plans) {
+		errs[indexes[i]] = err
+	}
+	return errs
+}
+
+// install downloads the files of all plans concurrently, then finishes the plans one after another.
+func (m *Manager) install(plans []*installPlan) []error {
+	errs := make([]error, len(plans))
+
+	var mu sync.Mutex
+	pending := make(map[string]struct{})
+	for _, plan := range plans {
+		pending[plan.rootPath] = struct{}{}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+	defer func() {
+		signal.Stop(sigs)
+		close(sigs)
+	}()
+	go func() {
+		sig, ok := <-sigs
+		if !ok {
+			return
+		}
+		// Kill the commands run by the PostInstall hook, the lock is held until the plan being
+		// finished returns, that is until the killed commands have exited.
+		cancel()
+		mu.Lock()
+		// Delete the directories of unfinished installations
+		for path := range pending {
+			_ = os.RemoveAll(path)
+		}
+		code := 1
+		if s, ok := sig.(syscall.Signal); ok {
+			code = 128 + int(s)
+		}
+		os.Exit(code)
+	}()
+
+	var tasks []*downloadTask
+	for _, plan := range plans {
+		tasks = append(tasks, plan.downloads...)
+	}
+	m.download(tasks)
+
+	for i, plan := range plans {
+		mu.Lock()
+		if ctx.Err() != nil {
+			mu.Unlock()
+			break
+		}
+		err := plan.finish(ctx)
+		if err != nil {
+			// Delete directory after failed installation
+			_ = os.RemoveAll(plan.rootPath)
+		}
+		delete(pending, plan.rootPath)
+		mu.Unlock()
+		errs[i] = err
+	}
+	if ctx.Err() != nil {
+		// Interrupted, wait for the signal handler to clean up and exit.
+		select {}
+	}
+	if err := m.lockProject(plans, errs); err != nil {
+		pterm.Printf("Failed to update %s, err: %s\n", projectLockFilename, err)
+	}
+	for _, err := range errs {
+		if err == nil {
+			m.refreshShims()
+			break
+		}
+	}
+	return errs
+}
+
+// lockProject records the files of the installed plans in the project lock file, if the project
+// has a lock file or Config.Project.Lockfile is enabled.
+func (m *Manager) lockProject(plans []*installPlan, errs []error) error {
+	lockPath := m.projectLockPath(m.Config.Project.Lockfile)
+	if lockPath == "" {
+		return nil
+	}
+	lock, err := LoadProjectLock(lockPath)
+	if err != nil {
+		return err
+	}
+	changed := false
+	for i, plan := range plans {
+		if errs[i] != nil || plan.frozen {
+			continue
+		}
+		locked := &LockedPackage{}
+		for j, info := range plan.infos() {
+			file, err := newLockedFile(info, plan.downloadTask(info))
+			if err != nil {
+				return err
+			}
+			if j == 0 {
+				locked.Main = file
+			} else {
+				locked.Additions = append(locked.Additions, file)
+			}
+		}
+		lock.lock(strings.ToLower(plan.sdk.Plugin.SdkName), plan.pkg.Main.Version, m.platform(), locked)
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	return lock.Save()
+}
+
+// projectLockPath returns the nearest project lock file in the working directory or its parents.
+// If there is none, the path in the working directory is returned if create is true, otherwise empty.
+func (m *Manager) projectLockPath(create bool) string {
+	dir := m.PathMeta.WorkingDirectory
+	stopMarker := m.Config.Project.StopMarker
+	for {
+		path := filepath.Join(dir, projectLockFilename)
+		if util.FileExists(path) {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir || (stopMarker != "" && util.FileExists(filepath.Join(dir, stopMarker))) {
+			break
+		}
+		dir = parent
+	}
+	if create {
+		return filepath.Join(m.PathMeta.WorkingDirectory, projectLockFilename)
+	}
+	return ""
+}
+
+func (m *Manager) platform() string {
+	return fmt.Sprintf("%s-%s", m.osType, m.archType)
+}
+
+// download downloads the files concurrently, at most Config.Download.Concurrency files at the same time,
+// and renders the progress of all files together.
+func (m *Manager) download(tasks []*downloadTask) {
+	if len(tasks) == 0 {
+		return
+	}
+	for _, task := range tasks {
+		if task.info.Checksum.Digests() == nil {
+			pterm.Printf("%s: Checksum of %s is not provided, skip verify...\n", pterm.LightYellow("WARNING"), task.label)
+		}
+	}
+	pterm.Printf("Downloading %d file(s)...\n", len(tasks))
+	progress := printer.NewMultiProgress()
+	if err := progress.Start(); err != nil {
+		pterm.Printf("Failed to render progress, err: %s\n", err)
+	}
+	defer progress.Stop()
+
+	workers := make(chan struct{}, m.Config.Download.Workers())
+	var wg sync.WaitGroup
+	for _, task := range tasks {
+		writer := progress.NewWriter()
+		_, _ = fmt.Fprintf(writer, "%s waiting...", task.label)
+		wg.Add(1)
+		go func(task *downloadTask) {
+			defer wg.Done()
+			workers <- struct{}{}
+			defer func() { <-workers }()
+			task.entry, task.err = task.sdk.download(task.url, task.info.Checksum, task.label, writer)
+			if task.err == nil {
+				task.path = task.entry.FilePath()
+			} else {
+				_, _ = fmt.Fprintf(writer, "\r%s %s", task.label, pterm.Red("failed: "+task.err.Error()))
+			}
+		}(task)
+	}
+	wg.Wait()
+}
@@ -18,44 +18,70 @@ package internal
 
 import (
 	"fmt"
+
+	"github.com/version-fox/vfox/internal/util"
 )
 
 type LuaCheckSum struct {
-	Sha256 string `luai:"sha256"`
-	Sha512 string `luai:"sha512"`
-	Sha1   string `luai:"sha1"`
-	Md5    string `luai:"md5"`
+	Sha256     string `luai:"sha256"`
+	Sha512     string `luai:"sha512"`
+	Sha384     string `luai:"sha384"`
+	Sha3_256   string `luai:"sha3_256"`
+	Sha3_384   string `luai:"sha3_384"`
+	Sha3_512   string `luai:"sha3_512"`
+	Blake2b256 string `luai:"blake2b_256"`
+	Blake2b512 string `luai:"blake2b_512"`
+	Sha1       string `luai:"sha1"`
+	Md5        string `luai:"md5"`
 }
 
+// Checksum returns all digests provided by the plugin, all of them must match.
 func (c *LuaCheckSum) Checksum() *Checksum {
-	checksum := &Checksum{}
-
-	if c.Sha256 != "" {
-		checksum.Value = c.Sha256
-		checksum.Type = "sha256"
-	} else if c.Md5 != "" {
-		checksum.Value = c.Md5
-		checksum.Type = "md5"
-	} else if c.Sha1 != "" {
-		checksum.Value = c.Sha1
-		checksum.Type = "sha1"
-	} else if c.Sha512 != "" {
-		checksum.Value = c.Sha512
-		checksum.Type = "sha512"
-	} else {
-		return NoneChecksum
-	}
+	return newChecksum(map[string]string{
+		"sha256":      c.Sha256,
+		"sha512":      c.Sha512,
+		"sha384":      c.Sha384,
+		"sha3-256":    c.Sha3_256,
+		"sha3-384":    c.Sha3_384,
+		"sha3-512":    c.Sha3_512,
+		"blake2b-256": c.Blake2b256,
+		"blake2b-512": c.Blake2b512,
+		"sha1":        c.Sha1,
+		"md5":         c.Md5,
+	})
+}
+
+// LuaDecompressOptions customizes how a downloaded archive is unpacked.
+type LuaDecompressOptions struct {
+	// Format is one of tar.gz, tgz, tar.xz, tar and zip, it is detected from the file extension if empty.
+	Format string `luai:"format"`
+	// Strip is the number of leading path components to be removed, the top-level directory is removed if nil.
+	Strip *int `luai:"strip"`
+}
 
-	return checksum
+func (o *LuaDecompressOptions) Options() *util.DecompressOptions {
+	if o == nil {
+		return nil
+	}
+	opts := &util.DecompressOptions{Format: o.Format, Strip: util.DefaultStrip}
+	if o.Strip != nil {
+		opts.Strip = *o.Strip
+	}
+	return opts
 }
 
 type AvailableHookCtx struct {
 	RuntimeVersion string `luai:"runtimeVersion"`
+	// Args are the extra arguments of `vfox search`, such as `temurin` in `vfox search java temurin`,
+	// which the plugin can use to filter the versions.
+	Args []string `luai:"args"`
 }
 
 type AvailableHookResultItem struct {
 	Version string `luai:"version"`
 	Note    string `luai:"note"`
+	// Aliases of the version, such as lts or stable
+	Aliases []string `luai:"aliases"`
 
 	Addition []*Info `luai:"addition"`
 }
@@ -71,26 +97,51 @@ type PreInstallHookResultAdditionItem struct {
 	Name string `luai:"name"`
 	Url  string `luai:"url"`
 
-	Sha256 string `luai:"sha256"`
-	Sha512 string `luai:"sha512"`
-	Sha1   string `luai:"sha1"`
-	Md5    string `luai:"md5"`
+	Sha256     string `luai:"sha256"`
+	Sha512     string `luai:"sha512"`
+	Sha384     string `luai:"sha384"`
+	Sha3_256   string `luai:"sha3_256"`
+	Sha3_384   string `luai:"sha3_384"`
+	Sha3_512   string `luai:"sha3_512"`
+	Blake2b256 string `luai:"blake2b_256"`
+	Blake2b512 string `luai:"blake2b_512"`
+	Sha1       string `luai:"sha1"`
+	Md5        string `luai:"md5"`
+
+	SignatureUrl  string `luai:"signature_url"`
+	SignatureType string `luai:"signature_type"`
+	PublicKey     string `luai:"public_key"`
+
+	Decompress *LuaDecompressOptions `luai:"decompress"`
 }
 
 func (i *PreInstallHookResultAdditionItem) Info() *Info {
 	sum := LuaCheckSum{
-		Sha256: i.Sha256,
-		Sha512: i.Sha512,
-		Sha1:   i.Sha1,
-		Md5:    i.Md5,
+		Sha256:     i.Sha256,
+		Sha512:     i.Sha512,
+		Sha384:     i.Sha384,
+		Sha3_256:   i.Sha3_256,
+		Sha3_384:   i.Sha3_384,
+		Sha3_512:   i.Sha3_512,
+		Blake2b256: i.Blake2b256,
+		Blake2b512: i.Blake2b512,
+		Sha1:       i.Sha1,
+		Md5:        i.Md5,
+	}
+	signature := LuaSignature{
+		Url:       i.SignatureUrl,
+		Type:      i.SignatureType,
+		PublicKey: i.PublicKey,
 	}
 
 	return &Info{
-		Name:     i.Name,
-		Version:  Version(""),
-		Path:     i.Url,
-		Note:     "",
-		Checksum: sum.Checksum(),
+		Name:       i.Name,
+		Version:    Version(""),
+		Path:       i.Url,
+		Note:       "",
+		Checksum:   sum.Checksum(),
+		Signature:  signature.Signature(),
+		Decompress: i.Decompress.Options(),
 	}
 }
 
@@ -98,10 +149,22 @@ type PreInstallHookResult struct {
 	Version string `luai:"version"`
 	Url     string `luai:"url"`
 
-	Sha256 string `luai:"sha256"`
-	Sha512 string `luai:"sha512"`
-	Sha1   string `luai:"sha1"`
-	Md5    string `luai:"md5"`
+	Sha256     string `luai:"sha256"`
+	Sha512     string `luai:"sha512"`
+	Sha384     string `luai:"sha384"`
+	Sha3_256   string `luai:"sha3_256"`
+	Sha3_384   string `luai:"sha3_384"`
+	Sha3_512   string `luai:"sha3_512"`
+	Blake2b256 string `luai:"blake2b_256"`
+	Blake2b512 string `luai:"blake2b_512"`
+	Sha1       string `luai:"sha1"`
+	Md5        string `luai:"md5"`
+
+	SignatureUrl  string `luai:"signature_url"`
+	SignatureType string `luai:"signature_type"`
+	PublicKey     string `luai:"public_key"`
+
+	Decompress *LuaDecompressOptions `luai:"decompress"`
 
 	Addition []*PreInstallHookResultAdditionItem `luai:"addition"`
 }
@@ -112,18 +175,31 @@ func (i *PreInstallHookResult) Info() (*Info, error) {
 	}
 
 	sum := LuaCheckSum{
-		Sha256: i.Sha256,
-		Sha512: i.Sha512,
-		Sha1:   i.Sha1,
-		Md5:    i.Md5,
+		Sha256:     i.Sha256,
+		Sha512:     i.Sha512,
+		Sha384:     i.Sha384,
+		Sha3_256:   i.Sha3_256,
+		Sha3_384:   i.Sha3_384,
+		Sha3_512:   i.Sha3_512,
+		Blake2b256: i.Blake2b256,
+		Blake2b512: i.Blake2b512,
+		Sha1:       i.Sha1,
+		Md5:        i.Md5,
+	}
+	signature := LuaSignature{
+		Url:       i.SignatureUrl,
+		Type:      i.SignatureType,
+		PublicKey: i.PublicKey,
 	}
 
 	return &Info{
-		Name:     "",
-		Version:  Version(i.Version),
-		Path:     i.Url,
-		Note:     "",
-		Checksum: sum.Checksum(),
+		Name:       "",
+		Version:    Version(i.Version),
+		Path:       i.Url,
+		Note:       "",
+		Checksum:   sum.Checksum(),
+		Signature:  signature.Signature(),
+		Decompress: i.Decompress.Options(),
 	}, nil
 }
 
@@ -140,6 +216,16 @@ type PreUseHookResult struct {
 	Version string `luai:"version"`
 }
 
+type ParseLegacyFileHookCtx struct {
+	RuntimeVersion string `luai:"runtimeVersion"`
+	Filepath       string `luai:"filepath"`
+	Filename       string `luai:"filename"`
+}
+
+type ParseLegacyFileHookResult struct {
+	Version string `luai:"version"`
+}
+
 type PostInstallHookCtx struct {
 	RuntimeVersion string           `luai:"runtimeVersion"`
 	RootPath       string           `luai:"rootPath"`
@@ -166,4 +252,7 @@ type LuaPluginInfo struct {
 	Description       string `luai:"description"`
 	UpdateUrl         string `luai:"updateUrl"`
 	MinRuntimeVersion string `luai:"minRuntimeVersion"`
+	// LegacyFilenames are the version files of other tools, such as .nvmrc,
+	// which are read by the ParseLegacyFile hook.
+	LegacyFilenames []string `luai:"legacyFilenames"`
 }
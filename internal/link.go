@@ -0,0 +1,69 @@
+/*
+ *    Copyright 2024 Han Li and contributors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pterm/pterm"
+	"github.com/version-fox/vfox/internal/env"
+)
+
+// Link registers a directory installed outside vfox as a version, by symlinking it into
+// the version path in the layout of an installed version.
+func (b *Sdk) Link(version Version, path string) error {
+	if version == "" || version == env.SystemVersion {
+		return fmt.Errorf("invalid version to link: %q", version)
+	}
+	label := b.label(version)
+	if b.checkExists(version) {
+		return fmt.Errorf("%s is %w", label, ErrAlreadyInstalled)
+	}
+	target, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(target)
+	if err != nil {
+		return fmt.Errorf("failed to link %s, err: %w", target, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("failed to link %s, it is not a directory", target)
+	}
+	versionPath := b.VersionPath(version)
+	if err = os.MkdirAll(versionPath, 0755); err != nil {
+		return fmt.Errorf("failed to create directory, err:%w", err)
+	}
+	main := &Info{Name: b.Plugin.Name, Version: version}
+	if err = os.Symlink(target, main.storagePath(versionPath)); err != nil {
+		_ = os.RemoveAll(versionPath)
+		return fmt.Errorf("failed to link %s, err: %w", target, err)
+	}
+	pterm.Printf("Linked %s to %s\n", pterm.LightGreen(label), target)
+	pterm.Printf("Please use %s to use it.\n", pterm.LightBlue(fmt.Sprintf("vfox use %s", label)))
+	b.sdkManager.refreshShims()
+	return nil
+}
+
+// IsLinked reports whether the version is a directory installed outside vfox, see Link.
+func (b *Sdk) IsLinked(version Version) bool {
+	main := &Info{Name: b.Plugin.Name, Version: version}
+	info, err := os.Lstat(main.storagePath(b.VersionPath(version)))
+	return err == nil && info.Mode()&os.ModeSymlink != 0
+}
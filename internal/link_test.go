@@ -0,0 +1,93 @@
+/*
+ *    Copyright 2024 Han Li and contributors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package internal
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/version-fox/vfox/internal/env"
+)
+
+func TestLink(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need privileges on windows")
+	}
+	manager, _ := newTestManager(t)
+	sdk, err := manager.LookupSdk("nodejs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	target := t.TempDir()
+	if err = sdk.Link("custom", target); err != nil {
+		t.Fatal(err)
+	}
+	if err = sdk.Link("custom", target); !errors.Is(err, ErrAlreadyInstalled) {
+		t.Errorf("expected ErrAlreadyInstalled, got %v", err)
+	}
+	if err = sdk.Link("other", filepath.Join(target, "missing")); err == nil {
+		t.Error("expected an error for a missing directory")
+	}
+	if !sdk.IsLinked("custom") || sdk.IsLinked("20.0.0") {
+		t.Error("unexpected linked versions")
+	}
+	envs, err := sdk.EnvKeys("custom")
+	if err != nil {
+		t.Fatal(err)
+	}
+	linked := filepath.Join(sdk.VersionPath("custom"), "nodejs-custom")
+	if len(envs.Paths) != 1 || envs.Paths[0] != filepath.Join(linked, "bin") {
+		t.Errorf("unexpected paths: %v", envs.Paths)
+	}
+
+	// Uninstalling removes the link only
+	if err = sdk.Uninstall("custom"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(target); err != nil {
+		t.Errorf("the linked directory is removed: %s", err)
+	}
+}
+
+func TestSystemVersion(t *testing.T) {
+	manager, _ := newTestManager(t)
+	manager.Record = env.NewReadonlyRecord(map[string]string{"nodejs": env.SystemVersion}, nil)
+	sdk, err := manager.LookupSdk("nodejs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current := sdk.Current(); current != env.SystemVersion {
+		t.Errorf("unexpected current version: %s", current)
+	}
+	envs, err := manager.SdkEnvKeys([]Arg{{Name: "nodejs"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(envs.Paths) != 0 {
+		t.Errorf("expected no paths for the system version, got %v", envs.Paths)
+	}
+	envs, err = manager.EnvKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(envs.Paths) != 0 {
+		t.Errorf("expected no paths for the system version, got %v", envs.Paths)
+	}
+}
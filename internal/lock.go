@@ -0,0 +1,234 @@
+/*
+ *    Copyright 2024 Han Li and contributors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package internal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/version-fox/vfox/internal/util"
+	"gopkg.in/yaml.v3"
+)
+
+const pluginLockFilename = "vfox.lock"
+
+// PluginLock records the source and the content hash of each plugin,
+// so that exactly the same plugins can be installed on another machine.
+type PluginLock struct {
+	Plugins map[string]*LockedPlugin `yaml:"plugins"`
+	path    string
+}
+
+type LockedPlugin struct {
+	Url     string `yaml:"url"`
+	Version string `yaml:"version"`
+	Sha256  string `yaml:"sha256"`
+}
+
+// verify checks the content of the plugin against the locked hash.
+func (p *LockedPlugin) verify(content string) error {
+	if actual := pluginChecksum(content); !strings.EqualFold(p.Sha256, actual) {
+		return &ChecksumError{Type: "sha256", Expected: p.Sha256, Actual: actual}
+	}
+	return nil
+}
+
+// LoadPluginLock reads the lock file, an empty lock is returned if the file does not exist.
+func LoadPluginLock(path string) (*PluginLock, error) {
+	lock := &PluginLock{
+		Plugins: make(map[string]*LockedPlugin),
+		path:    path,
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return lock, nil
+		}
+		return nil, err
+	}
+	if err = yaml.Unmarshal(content, lock); err != nil {
+		return nil, fmt.Errorf("parse %s failed, err: %w", path, err)
+	}
+	if lock.Plugins == nil {
+		lock.Plugins = make(map[string]*LockedPlugin)
+	}
+	return lock, nil
+}
+
+func (l *PluginLock) Save() error {
+	content, err := yaml.Marshal(l)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(l.path, content, 0644)
+}
+
+// pluginChecksum returns the sha256 of the plugin content.
+func pluginChecksum(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+const projectLockFilename = ".tool-versions.lock"
+
+// ProjectLock pins the files of the installed sdk versions of a project for each platform,
+// which are keyed by sdk name, version and platform in turn.
+type ProjectLock struct {
+	Sdks map[string]map[string]map[string]*LockedPackage `yaml:"sdks"`
+	path string
+}
+
+type LockedPackage struct {
+	Main      *LockedFile   `yaml:"main"`
+	Additions []*LockedFile `yaml:"additions,omitempty"`
+}
+
+// LockedFile is a file of a sdk, the checksums are keyed by the hash algorithm.
+type LockedFile struct {
+	Name          string            `yaml:"name,omitempty"`
+	Version       string            `yaml:"version,omitempty"`
+	Url           string            `yaml:"url"`
+	Checksums     map[string]string `yaml:"checksums,omitempty"`
+	SignatureUrl  string            `yaml:"signature_url,omitempty"`
+	SignatureType string            `yaml:"signature_type,omitempty"`
+	PublicKey     string            `yaml:"public_key,omitempty"`
+	// Decompress is locked only if the plugin customizes the unpacking.
+	Decompress *util.DecompressOptions `yaml:"decompress,omitempty"`
+}
+
+// LoadProjectLock reads the lock file, an empty lock is returned if the file does not exist.
+func LoadProjectLock(path string) (*ProjectLock, error) {
+	lock := &ProjectLock{
+		Sdks: make(map[string]map[string]map[string]*LockedPackage),
+		path: path,
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return lock, nil
+		}
+		return nil, err
+	}
+	if err = yaml.Unmarshal(content, lock); err != nil {
+		return nil, fmt.Errorf("parse %s failed, err: %w", path, err)
+	}
+	if lock.Sdks == nil {
+		lock.Sdks = make(map[string]map[string]map[string]*LockedPackage)
+	}
+	return lock, nil
+}
+
+func (l *ProjectLock) Save() error {
+	content, err := yaml.Marshal(l)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(l.path, content, 0644)
+}
+
+// Lookup returns the locked version matching the version or the version constraint,
+// and the locked package of the platform.
+func (l *ProjectLock) Lookup(name string, version Version, platform string) (Version, *LockedPackage, error) {
+	versions := l.Sdks[name]
+	resolved := version
+	if _, ok := versions[string(version)]; !ok {
+		candidates := make([]*Package, 0, len(versions))
+		for v := range versions {
+			candidates = append(candidates, &Package{Main: &Info{Version: Version(v)}})
+		}
+		resolved = resolveVersion(version, candidates)
+	}
+	platforms, ok := versions[string(resolved)]
+	if !ok {
+		return "", nil, fmt.Errorf("%s@%s is not locked in %s", name, version, l.path)
+	}
+	pkg, ok := platforms[platform]
+	if !ok {
+		return "", nil, fmt.Errorf("%s@%s is not locked for %s in %s", name, resolved, platform, l.path)
+	}
+	return resolved, pkg, nil
+}
+
+func (l *ProjectLock) lock(name string, version Version, platform string, pkg *LockedPackage) {
+	versions, ok := l.Sdks[name]
+	if !ok {
+		versions = make(map[string]map[string]*LockedPackage)
+		l.Sdks[name] = versions
+	}
+	platforms, ok := versions[string(version)]
+	if !ok {
+		platforms = make(map[string]*LockedPackage)
+		versions[string(version)] = platforms
+	}
+	platforms[platform] = pkg
+}
+
+// newLockedFile records the file of the sdk. If the plugin does not provide a sha256 checksum,
+// it is computed from the downloaded file, so that the file can always be verified.
+func newLockedFile(info *Info, task *downloadTask) (*LockedFile, error) {
+	file := &LockedFile{
+		Name:      info.Name,
+		Version:   string(info.Version),
+		Url:       info.Path,
+		Checksums: make(map[string]string),
+	}
+	for _, digest := range info.Checksum.Digests() {
+		file.Checksums[digest.Type] = strings.ToLower(digest.Value)
+	}
+	if task != nil && task.path != "" && file.Checksums["sha256"] == "" {
+		sum, err := digestFile(task.path, "sha256")
+		if err != nil {
+			return nil, err
+		}
+		file.Checksums["sha256"] = sum
+	}
+	file.Decompress = info.Decompress
+	if info.Signature != nil {
+		file.SignatureUrl = info.Signature.Url
+		file.SignatureType = info.Signature.Type
+		file.PublicKey = info.Signature.PublicKey
+	}
+	return file, nil
+}
+
+func (f *LockedFile) info() *Info {
+	signature := LuaSignature{
+		Url:       f.SignatureUrl,
+		Type:      f.SignatureType,
+		PublicKey: f.PublicKey,
+	}
+	return &Info{
+		Name:       f.Name,
+		Version:    Version(f.Version),
+		Path:       f.Url,
+		Checksum:   newChecksum(f.Checksums),
+		Signature:  signature.Signature(),
+		Decompress: f.Decompress,
+	}
+}
+
+// Package returns the package to be installed exactly as locked.
+func (p *LockedPackage) Package() *Package {
+	pkg := &Package{Main: p.Main.info()}
+	for _, addition := range p.Additions {
+		pkg.Additions = append(pkg.Additions, addition.info())
+	}
+	return pkg
+}
@@ -0,0 +1,200 @@
+/*
+ *    Copyright 2024 Han Li and contributors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package internal
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/version-fox/vfox/internal/config"
+)
+
+func TestSyncPlugins(t *testing.T) {
+	dir := t.TempDir()
+	meta := &PathMeta{
+		ConfigPath: dir,
+		PluginPath: filepath.Join(dir, "plugin"),
+	}
+	manager := &Manager{
+		PathMeta: meta,
+		Config:   &config.Config{Proxy: config.EmptyProxy},
+	}
+	source := filepath.Join(dir, "nodejs.lua")
+	content := "PLUGIN = {}"
+	if err := os.WriteFile(source, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	lockPath := filepath.Join(dir, "project.lock")
+	lock, err := LoadPluginLock(lockPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lock.Plugins["nodejs"] = &LockedPlugin{Url: source, Version: "0.0.1", Sha256: pluginChecksum(content)}
+	if err = lock.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = manager.SyncPlugins(lockPath); err != nil {
+		t.Fatal(err)
+	}
+	synced, err := os.ReadFile(filepath.Join(meta.PluginPath, "nodejs", "main.lua"))
+	if err != nil || string(synced) != content {
+		t.Fatalf("expected the plugin to be synced, got %q, err: %v", synced, err)
+	}
+	global, err := LoadPluginLock(manager.pluginLockPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if global.Plugins["nodejs"] == nil || global.Plugins["nodejs"].Version != "0.0.1" {
+		t.Errorf("expected the synced plugin to be recorded in the global lock")
+	}
+
+	// the upstream content has changed
+	if err = os.WriteFile(source, []byte("PLUGIN = { name = 'evil' }"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.RemoveAll(meta.PluginPath); err != nil {
+		t.Fatal(err)
+	}
+	err = manager.SyncPlugins(lockPath)
+	if err == nil || !strings.Contains(err.Error(), "drifted") {
+		t.Fatalf("expected a drift error, got %v", err)
+	}
+	if _, err = os.Stat(filepath.Join(meta.PluginPath, "nodejs", "main.lua")); !os.IsNotExist(err) {
+		t.Errorf("the drifted plugin should not be written")
+	}
+
+	// the names in a shared lock file must not escape the plugin directory
+	for _, name := range []string{"../../evil", "a/b", ".", ""} {
+		lock.Plugins = map[string]*LockedPlugin{name: {Url: source, Sha256: pluginChecksum(content)}}
+		if err = lock.Save(); err != nil {
+			t.Fatal(err)
+		}
+		if err = manager.SyncPlugins(lockPath); err == nil || !strings.Contains(err.Error(), "invalid plugin name") {
+			t.Errorf("expected an invalid plugin name error for %q, got %v", name, err)
+		}
+	}
+}
+
+const frozenPlugin = `
+PLUGIN = { name = "demo", version = "0.0.1" }
+function PLUGIN:Available(ctx) return {} end
+function PLUGIN:PreInstall(ctx) error("PreInstall must not be called in frozen mode") end
+function PLUGIN:EnvKeys(ctx) return {} end
+`
+
+func TestInstallFrozen(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("vfox"))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	meta := &PathMeta{
+		TempPath:         filepath.Join(dir, "temp"),
+		SdkCachePath:     filepath.Join(dir, "cache"),
+		PluginPath:       filepath.Join(dir, "plugin"),
+		WorkingDirectory: dir,
+	}
+	manager := &Manager{
+		PathMeta: meta,
+		Config: &config.Config{
+			Proxy:     config.EmptyProxy,
+			Project:   config.EmptyProject,
+			Download:  config.EmptyDownload,
+			Signature: config.EmptySignature,
+		},
+		osType:   "linux",
+		archType: "amd64",
+	}
+	manager.DownloadCache = newDownloadCache(meta)
+	manager.openSdks = make(map[string]*Sdk)
+	pluginPath := filepath.Join(meta.PluginPath, "demo", "main.lua")
+	if err := os.MkdirAll(filepath.Dir(pluginPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(pluginPath, []byte(frozenPlugin), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sdk, err := manager.LookupSdk("demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if errs := manager.InstallFrozen([]Arg{{Name: "demo", Version: "1.0.0"}}); errs[0] == nil {
+		t.Fatalf("expected an error without the lock file")
+	}
+
+	lock, err := LoadProjectLock(filepath.Join(dir, projectLockFilename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := &LockedFile{
+		Name:      "demo",
+		Version:   "1.0.0",
+		Url:       server.URL + "/demo.txt",
+		Checksums: map[string]string{"sha256": "xxx"},
+	}
+	lock.lock("demo", "1.0.0", manager.platform(), &LockedPackage{Main: file})
+	if err = lock.Save(); err != nil {
+		t.Fatal(err)
+	}
+	errs := manager.InstallFrozen([]Arg{{Name: "demo", Version: "1"}})
+	var checksumErr *ChecksumError
+	if !errors.As(errs[0], &checksumErr) {
+		t.Fatalf("expected a checksum mismatch, got %v", errs[0])
+	}
+	if errs = manager.InstallFrozen([]Arg{{Name: "demo", Version: "2.0.0"}}); errs[0] == nil || !strings.Contains(errs[0].Error(), "not locked") {
+		t.Fatalf("expected the unlocked version to be rejected, got %v", errs[0])
+	}
+
+	file.Checksums["sha256"] = "29158b777949f4dae1eb3e613da6146b5afd266254f922892f88c41963b71119"
+	if err = lock.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if errs = manager.InstallFrozen([]Arg{{Name: "demo", Version: "1"}}); errs[0] != nil {
+		t.Fatal(errs[0])
+	}
+	if !sdk.checkExists("1.0.0") {
+		t.Errorf("expected demo@1.0.0 to be installed")
+	}
+}
+
+func TestLockProject(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "demo.txt")
+	if err := os.WriteFile(path, []byte("vfox"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	info := &Info{Name: "demo", Version: "1.0.0", Path: "https://example.com/demo.txt", Checksum: NoneChecksum}
+	file, err := newLockedFile(info, &downloadTask{info: info, path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file.Checksums["sha256"] != "29158b777949f4dae1eb3e613da6146b5afd266254f922892f88c41963b71119" {
+		t.Errorf("expected the sha256 to be computed from the downloaded file, got %v", file.Checksums)
+	}
+	restored := file.info()
+	if restored.Path != info.Path || restored.Checksum.Type != "sha256" {
+		t.Errorf("unexpected locked file: %+v", restored)
+	}
+}
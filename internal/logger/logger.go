@@ -16,7 +16,10 @@
 
 package logger
 
-import "fmt"
+import (
+	"fmt"
+	"os"
+)
 
 type LoggerLevel int
 
@@ -33,15 +36,16 @@ func SetLevel(_level LoggerLevel) {
 	currentLevel = _level
 }
 
+// Log writes to stderr, so that the stdout only carries the output of the command, such as json.
 func Log(level LoggerLevel, args ...interface{}) {
 	if currentLevel <= level {
-		fmt.Println(args...)
+		fmt.Fprintln(os.Stderr, args...)
 	}
 }
 
 func Logf(level LoggerLevel, message string, args ...interface{}) {
 	if currentLevel <= level {
-		fmt.Printf(message, args...)
+		fmt.Fprintf(os.Stderr, message, args...)
 	}
 }
 
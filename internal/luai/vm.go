@@ -18,6 +18,9 @@ package luai
 
 import (
 	_ "embed"
+	"fmt"
+	"os"
+	"strings"
 
 	"github.com/version-fox/vfox/internal/config"
 	"github.com/version-fox/vfox/internal/module"
@@ -33,12 +36,25 @@ type LuaVM struct {
 
 func NewLuaVM() *LuaVM {
 	instance := lua.NewState()
+	instance.SetGlobal("print", instance.NewFunction(luaPrint))
 
 	return &LuaVM{
 		Instance: instance,
 	}
 }
 
+// luaPrint replaces the print of lua, it writes to stderr like the logger, so that the stdout
+// only carries the output of the command.
+func luaPrint(L *lua.LState) int {
+	top := L.GetTop()
+	args := make([]string, 0, top)
+	for i := 1; i <= top; i++ {
+		args = append(args, L.ToStringMeta(L.Get(i)).String())
+	}
+	_, _ = fmt.Fprintln(os.Stderr, strings.Join(args, "\t"))
+	return 0
+}
+
 type PrepareOptions struct {
 	Config *config.Config
 }
@@ -24,18 +24,23 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/pterm/pterm"
 	"github.com/version-fox/vfox/internal/config"
 	"github.com/version-fox/vfox/internal/env"
+	"github.com/version-fox/vfox/internal/logger"
 	"github.com/version-fox/vfox/internal/util"
+	"gopkg.in/yaml.v3"
 )
 
 const (
 	pluginIndexUrl      = "https://version-fox.github.io/version-fox-plugins/"
 	cleanupFlagFilename = ".cleanup"
+	// pluginMetadataFilename is the name of the metadata file in the directory of each plugin.
+	pluginMetadataFilename = ".metadata.yaml"
 )
 
 type Arg struct {
@@ -44,23 +49,25 @@ type Arg struct {
 }
 
 type Manager struct {
-	PathMeta   *PathMeta
-	openSdks   map[string]*Sdk
-	EnvManager env.Manager
-	Record     env.Record
-	osType     util.OSType
-	archType   util.ArchType
-	Config     *config.Config
+	PathMeta      *PathMeta
+	DownloadCache *DownloadCache
+	openSdks      map[string]*Sdk
+	EnvManager    env.Manager
+	Record        env.Record
+	osType        util.OSType
+	archType      util.ArchType
+	Config        *config.Config
 }
 
+// EnvKeys returns the environment of the recorded sdk versions, the sdks are in the order of their names.
 func (m *Manager) EnvKeys() (*env.Envs, error) {
 	shellEnvs := &env.Envs{
 		Variables: make(env.Vars),
 		Paths:     make(env.Paths, 0),
 	}
-	for k, v := range m.Record.Export() {
+	for _, k := range m.recordedSdks() {
 		if lookupSdk, err := m.LookupSdk(k); err == nil {
-			if ek, err := lookupSdk.EnvKeys(Version(v)); err == nil {
+			if ek, err := lookupSdk.EnvKeys(lookupSdk.Current()); err == nil {
 				for key, value := range ek.Variables {
 					shellEnvs.Variables[key] = value
 				}
@@ -71,6 +78,53 @@ func (m *Manager) EnvKeys() (*env.Envs, error) {
 	return shellEnvs, nil
 }
 
+// recordedSdks returns the names of the recorded sdks in order, so that the binaries are
+// always looked up in the same order.
+func (m *Manager) recordedSdks() []string {
+	records := m.Record.Export()
+	names := make([]string, 0, len(records))
+	for name := range records {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SdkEnvKeys returns the environment of the specified sdk versions, which can be
+// version constraints resolved against the installed versions. The recorded version
+// is used if the version of a sdk is empty, and the sdks of the system version are skipped.
+func (m *Manager) SdkEnvKeys(args []Arg) (*env.Envs, error) {
+	envs := &env.Envs{
+		Variables: make(env.Vars),
+		Paths:     make(env.Paths, 0),
+	}
+	for _, arg := range args {
+		sdk, err := m.LookupSdk(arg.Name)
+		if err != nil {
+			return nil, fmt.Errorf("%s not supported, error: %w", arg.Name, err)
+		}
+		version := Version(arg.Version)
+		if version == "" {
+			version = sdk.Current()
+		}
+		if version == env.SystemVersion {
+			continue
+		}
+		if !sdk.checkExists(version) {
+			version = sdk.resolveLocalVersion(version)
+		}
+		keys, err := sdk.EnvKeys(version)
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range keys.Variables {
+			envs.Variables[key] = value
+		}
+		envs.Paths = append(envs.Paths, keys.Paths...)
+	}
+	return envs, nil
+}
+
 // LookupSdk lookup sdk by name
 func (m *Manager) LookupSdk(name string) (*Sdk, error) {
 	pluginPath := filepath.Join(m.PathMeta.PluginPath, strings.ToLower(name), "main.lua")
@@ -141,6 +195,102 @@ func (m *Manager) LoadAllSdk() (map[string]*Sdk, error) {
 	return sdkMap, nil
 }
 
+// loadLegacyRecord reads the versions from the legacy version files declared by the plugins,
+// such as .nvmrc. The nearest file in the working directory or its parents is used.
+func (m *Manager) loadLegacyRecord() env.Record {
+	versions := make(map[string]string)
+	origins := make(map[string]string)
+	dir, err := os.ReadDir(m.PathMeta.PluginPath)
+	if err != nil {
+		return env.EmptyRecord
+	}
+	for _, d := range dir {
+		if !d.IsDir() {
+			continue
+		}
+		filenames := m.legacyFilenames(d.Name())
+		if len(filenames) == 0 {
+			continue
+		}
+		path := m.findLegacyFile(filenames)
+		if path == "" {
+			continue
+		}
+		// Only the plugins with a legacy file found are loaded
+		sdk, err := m.LookupSdk(d.Name())
+		if err != nil {
+			continue
+		}
+		version, err := sdk.Plugin.ParseLegacyFile(path)
+		if err != nil {
+			logger.Debugf("Failed to parse %s, err: %s\n", path, err)
+			continue
+		}
+		if version == "" {
+			continue
+		}
+		versions[sdk.Plugin.SdkName] = string(version)
+		origins[sdk.Plugin.SdkName] = path
+	}
+	return env.NewReadonlyRecord(versions, origins)
+}
+
+// pluginMetadata caches the fields of a plugin which are needed without loading it,
+// it is refreshed whenever the content of the plugin changes.
+type pluginMetadata struct {
+	Sha256          string   `yaml:"sha256"`
+	LegacyFilenames []string `yaml:"legacy_filenames,omitempty"`
+}
+
+// legacyFilenames returns the legacy version filenames declared by the plugin. They are read from
+// the metadata of the plugin, so that the plugin is not loaded on every prompt.
+func (m *Manager) legacyFilenames(name string) []string {
+	dir := filepath.Join(m.PathMeta.PluginPath, name)
+	content, err := os.ReadFile(filepath.Join(dir, "main.lua"))
+	if err != nil {
+		return nil
+	}
+	checksum := pluginChecksum(string(content))
+	metadataPath := filepath.Join(dir, pluginMetadataFilename)
+	metadata := &pluginMetadata{}
+	if data, err := os.ReadFile(metadataPath); err == nil {
+		if yaml.Unmarshal(data, metadata) == nil && metadata.Sha256 == checksum {
+			return metadata.LegacyFilenames
+		}
+	}
+	sdk, err := m.LookupSdk(name)
+	if err != nil {
+		return nil
+	}
+	metadata = &pluginMetadata{Sha256: checksum, LegacyFilenames: sdk.Plugin.LegacyFilenames}
+	if data, err := yaml.Marshal(metadata); err == nil {
+		if err = os.WriteFile(metadataPath, data, 0644); err != nil {
+			logger.Debugf("Failed to write the metadata of %s plugin, err: %s\n", name, err)
+		}
+	}
+	return metadata.LegacyFilenames
+}
+
+// findLegacyFile returns the nearest file with one of the filenames in the working directory
+// or its parents, the search stops at the directory containing Config.Project.StopMarker.
+func (m *Manager) findLegacyFile(filenames []string) string {
+	dir := m.PathMeta.WorkingDirectory
+	stopMarker := m.Config.Project.StopMarker
+	for {
+		for _, filename := range filenames {
+			path := filepath.Join(dir, filename)
+			if util.FileExists(path) {
+				return path
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir || (stopMarker != "" && util.FileExists(filepath.Join(dir, stopMarker))) {
+			return ""
+		}
+		dir = parent
+	}
+}
+
 func (m *Manager) Close() {
 	for _, handler := range m.openSdks {
 		handler.Close()
@@ -164,6 +314,12 @@ func (m *Manager) Remove(pluginName string) error {
 	if err = os.RemoveAll(source.InstallPath); err != nil {
 		return err
 	}
+	if err = m.updatePluginLock(func(lock *PluginLock) {
+		delete(lock.Plugins, pluginName)
+	}); err != nil {
+		return err
+	}
+	m.refreshShims()
 	pterm.Printf("Remove %s plugin successfully! \n", pterm.LightGreen(pluginName))
 	return nil
 }
@@ -182,6 +338,11 @@ func (m *Manager) Update(pluginName string) error {
 	if err != nil {
 		return fmt.Errorf("fetch plugin failed, err: %w", err)
 	}
+	if remote := m.lookupRemotePlugin(updateUrl); remote != nil {
+		if err = verifyRemotePlugin(remote, content); err != nil {
+			return err
+		}
+	}
 	source, err := NewLuaPlugin(content, updateUrl, m)
 	if err != nil {
 		return fmt.Errorf("check %s plugin failed, err: %w", updateUrl, err)
@@ -210,11 +371,15 @@ func (m *Manager) Update(pluginName string) error {
 		return fmt.Errorf("update %s plugin failed: %w", updateUrl, err)
 	}
 	success = true
+	if err = m.lockPlugin(pluginName, updateUrl, source.Version, content); err != nil {
+		return err
+	}
 	pterm.Printf("Update %s plugin successfully! version: %s \n", pterm.LightGreen(pluginName), pterm.LightBlue(source.Version))
 	return nil
 }
 
 func (m *Manager) Add(pluginName, url, alias string) error {
+	var remote *RemotePluginInfo
 	// official plugin
 	if len(url) == 0 {
 		args := strings.Split(pluginName, "/")
@@ -232,6 +397,7 @@ func (m *Manager) Add(pluginName, url, alias string) error {
 				for _, p := range available.Plugins {
 					if name == p.Filename {
 						url = p.Url
+						remote = p
 						break
 					}
 				}
@@ -244,6 +410,11 @@ func (m *Manager) Add(pluginName, url, alias string) error {
 	if err != nil {
 		return fmt.Errorf("failed to load plugin: %w", err)
 	}
+	if remote != nil {
+		if err = verifyRemotePlugin(remote, content); err != nil {
+			return err
+		}
+	}
 	pterm.Println("Checking plugin...")
 	source, err := NewLuaPlugin(content, url, m)
 	if err != nil {
@@ -260,6 +431,9 @@ func (m *Manager) Add(pluginName, url, alias string) error {
 	if len(alias) > 0 {
 		pname = alias
 	}
+	if err = checkPluginName(pname); err != nil {
+		return err
+	}
 	destPath := filepath.Join(m.PathMeta.PluginPath, pname, "main.lua")
 	if util.FileExists(destPath) {
 		return fmt.Errorf("plugin %s already exists", pname)
@@ -270,6 +444,9 @@ func (m *Manager) Add(pluginName, url, alias string) error {
 	if err = os.WriteFile(destPath, []byte(content), 0777); err != nil {
 		return fmt.Errorf("add plugin error: %w", err)
 	}
+	if err = m.lockPlugin(pname, url, source.Version, content); err != nil {
+		return err
+	}
 	pterm.Println("Plugin info:")
 	pterm.Println("Name   ", "->", pterm.LightBlue(source.Name))
 	pterm.Println("Author ", "->", pterm.LightBlue(source.Author))
@@ -281,6 +458,132 @@ func (m *Manager) Add(pluginName, url, alias string) error {
 	return nil
 }
 
+// checkPluginName checks that the name is a single path element, which can be used as the directory of the plugin.
+func checkPluginName(name string) error {
+	if name == "." || !filepath.IsLocal(name) || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid plugin name: %q", name)
+	}
+	return nil
+}
+
+// SyncPlugins installs exactly the plugins recorded in the lock file, the global lock file
+// is used if lockPath is empty. Nothing is written if the content of any plugin does not
+// match the locked hash.
+func (m *Manager) SyncPlugins(lockPath string) error {
+	if lockPath == "" {
+		lockPath = m.pluginLockPath()
+	}
+	lock, err := LoadPluginLock(lockPath)
+	if err != nil {
+		return err
+	}
+	if len(lock.Plugins) == 0 {
+		return fmt.Errorf("no plugin is recorded in %s", lockPath)
+	}
+	names := make([]string, 0, len(lock.Plugins))
+	for name := range lock.Plugins {
+		if err = checkPluginName(name); err != nil {
+			return fmt.Errorf("%s: %w", lockPath, err)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	contents := make(map[string]string)
+	for _, name := range names {
+		locked := lock.Plugins[name]
+		path := filepath.Join(m.PathMeta.PluginPath, name, "main.lua")
+		if content, err := os.ReadFile(path); err == nil && locked.verify(string(content)) == nil {
+			pterm.Printf("%s plugin is up to date.\n", pterm.LightGreen(name))
+			continue
+		}
+		pterm.Printf("Loading %s plugin from %s...\n", name, locked.Url)
+		content, err := m.loadLuaFromFileOrUrl(locked.Url)
+		if err != nil {
+			return fmt.Errorf("failed to load %s plugin: %w", name, err)
+		}
+		if err = locked.verify(content); err != nil {
+			return fmt.Errorf("%s plugin has drifted from %s: %w", name, lockPath, err)
+		}
+		contents[name] = content
+	}
+
+	for _, name := range names {
+		content, ok := contents[name]
+		if !ok {
+			continue
+		}
+		path := filepath.Join(m.PathMeta.PluginPath, name, "main.lua")
+		if err = os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+			return fmt.Errorf("sync plugin error: %w", err)
+		}
+		if err = os.WriteFile(path, []byte(content), 0644); err != nil {
+			return fmt.Errorf("sync plugin error: %w", err)
+		}
+		pterm.Printf("Sync %s plugin successfully! version: %s \n", pterm.LightGreen(name), pterm.LightBlue(lock.Plugins[name].Version))
+	}
+	return m.updatePluginLock(func(global *PluginLock) {
+		for name, locked := range lock.Plugins {
+			global.Plugins[name] = locked
+		}
+	})
+}
+
+// lookupRemotePlugin finds the official plugin by its url, nil if not found.
+func (m *Manager) lookupRemotePlugin(u string) *RemotePluginInfo {
+	categories, err := m.Available()
+	if err != nil {
+		return nil
+	}
+	for _, category := range categories {
+		for _, p := range category.Plugins {
+			if p.Url == u {
+				return p
+			}
+		}
+	}
+	return nil
+}
+
+// verifyRemotePlugin checks the content of the plugin against the sha256 in the plugin index.
+func verifyRemotePlugin(remote *RemotePluginInfo, content string) error {
+	if remote.Sha256 == "" {
+		return nil
+	}
+	if actual := pluginChecksum(content); !strings.EqualFold(remote.Sha256, actual) {
+		return fmt.Errorf("the plugin does not match the plugin index: %w",
+			&ChecksumError{Type: "sha256", Expected: remote.Sha256, Actual: actual})
+	}
+	return nil
+}
+
+func (m *Manager) pluginLockPath() string {
+	return filepath.Join(m.PathMeta.ConfigPath, pluginLockFilename)
+}
+
+// lockPlugin records the source and the hash of the plugin in the lock file.
+func (m *Manager) lockPlugin(name, url, version, content string) error {
+	return m.updatePluginLock(func(lock *PluginLock) {
+		lock.Plugins[name] = &LockedPlugin{
+			Url:     url,
+			Version: version,
+			Sha256:  pluginChecksum(content),
+		}
+	})
+}
+
+func (m *Manager) updatePluginLock(update func(lock *PluginLock)) error {
+	lock, err := LoadPluginLock(m.pluginLockPath())
+	if err != nil {
+		return err
+	}
+	update(lock)
+	if err = lock.Save(); err != nil {
+		return fmt.Errorf("failed to save %s, err: %w", lock.path, err)
+	}
+	return nil
+}
+
 func (m *Manager) httpClient() *http.Client {
 	var client *http.Client
 	if m.Config.Proxy.Enable {
@@ -411,35 +714,47 @@ func newSdkManagerWithSource(sources ...RecordSource) *Manager {
 	if err != nil {
 		panic("Init path meta error")
 	}
+	c, err := config.NewConfig(meta.ConfigPath)
+	if err != nil {
+		panic(fmt.Errorf("init Config error: %w", err))
+	}
 
-	var paths []string
+	var records []env.Record
+	projectIndex := -1
 	for _, source := range sources {
+		var (
+			r   env.Record
+			err error
+		)
 		switch source {
 		case GlobalRecordSource:
-			paths = append(paths, meta.ConfigPath)
+			r, err = env.NewRecord(meta.ConfigPath)
 		case ProjectRecordSource:
-			paths = append(paths, meta.WorkingDirectory)
+			r, err = env.NewProjectRecord(meta.WorkingDirectory, c.Project.StopMarker)
+			projectIndex = len(records)
 		case SessionRecordSource:
-			paths = append(paths, meta.CurTmpPath)
+			r, err = env.NewRecord(meta.CurTmpPath)
+		default:
+			continue
 		}
-	}
-	var record env.Record
-	if len(paths) == 0 {
-		record = env.EmptyRecord
-	} else if len(paths) == 1 {
-		r, err := env.NewRecord(paths[0])
 		if err != nil {
 			panic(err)
 		}
-		record = r
+		records = append(records, r)
+	}
+	var record env.Record
+	if len(records) == 0 {
+		record = env.EmptyRecord
 	} else {
-		r, err := env.NewRecord(paths[0], paths[1:]...)
-		if err != nil {
-			panic(err)
-		}
-		record = r
+		record = env.NewMultiRecord(records[0], records[1:]...)
 	}
-	return newSdkManager(record, meta)
+	manager := newSdkManager(record, meta, c)
+	if projectIndex >= 0 && c.LegacyVersionFile.Enable {
+		// The legacy version files have lower priority than .tool-versions.
+		records[projectIndex] = env.NewMultiRecord(manager.loadLegacyRecord(), records[projectIndex])
+		manager.Record = env.NewMultiRecord(records[0], records[1:]...)
+	}
+	return manager
 }
 
 func NewSdkManager(sources ...RecordSource) *Manager {
@@ -449,15 +764,11 @@ func NewSdkManager(sources ...RecordSource) *Manager {
 	return newSdkManagerWithSource(sources...)
 }
 
-func newSdkManager(record env.Record, meta *PathMeta) *Manager {
+func newSdkManager(record env.Record, meta *PathMeta, c *config.Config) *Manager {
 	envManger, err := env.NewEnvManager(meta.ConfigPath)
 	if err != nil {
 		panic("Init env manager error")
 	}
-	c, err := config.NewConfig(meta.ConfigPath)
-	if err != nil {
-		panic(fmt.Errorf("init Config error: %w", err))
-	}
 
 	// custom sdk path first
 	if len(c.Storage.SdkPath) > 0 {
@@ -468,13 +779,14 @@ func newSdkManager(record env.Record, meta *PathMeta) *Manager {
 		meta.SdkCachePath = c.Storage.SdkPath
 	}
 	manager := &Manager{
-		PathMeta:   meta,
-		EnvManager: envManger,
-		Record:     record,
-		openSdks:   make(map[string]*Sdk),
-		osType:     util.GetOSType(),
-		archType:   util.GetArchType(),
-		Config:     c,
+		PathMeta:      meta,
+		DownloadCache: newDownloadCache(meta),
+		EnvManager:    envManger,
+		Record:        record,
+		openSdks:      make(map[string]*Sdk),
+		osType:        util.GetOSType(),
+		archType:      util.GetArchType(),
+		Config:        c,
 	}
 	return manager
 }
@@ -0,0 +1,122 @@
+/*
+ *    Copyright 2024 Han Li and contributors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/version-fox/vfox/internal/config"
+	"github.com/version-fox/vfox/internal/env"
+)
+
+const legacyPlugin = `
+PLUGIN = { name = "nodejs", version = "0.0.1", legacyFilenames = { ".nvmrc", ".node-version" } }
+function PLUGIN:Available(ctx) return {} end
+function PLUGIN:PreInstall(ctx) return {} end
+function PLUGIN:EnvKeys(ctx) return {} end
+function PLUGIN:ParseLegacyFile(ctx)
+    local f = io.open(ctx.filepath, "r")
+    local version = f:read("*l")
+    f:close()
+    return { version = string.gsub(version, "^lts/", "") }
+end
+`
+
+func TestLegacyRecord(t *testing.T) {
+	dir := t.TempDir()
+	project := filepath.Join(dir, "project")
+	workDir := filepath.Join(project, "sub")
+	pluginDir := filepath.Join(dir, "plugin")
+	for _, path := range []string{workDir, filepath.Join(pluginDir, "nodejs"), filepath.Join(pluginDir, "python"), filepath.Join(pluginDir, "ruby")} {
+		if err := os.MkdirAll(path, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files := map[string]string{
+		filepath.Join(pluginDir, "nodejs", "main.lua"): legacyPlugin,
+		filepath.Join(pluginDir, "python", "main.lua"): `
+PLUGIN = { name = "python", version = "0.0.1", legacyFilenames = { ".python-version" } }
+function PLUGIN:Available(ctx) return {} end
+function PLUGIN:PreInstall(ctx) return {} end
+function PLUGIN:EnvKeys(ctx) return {} end
+`,
+		filepath.Join(pluginDir, "ruby", "main.lua"): `
+PLUGIN = { name = "ruby", version = "0.0.1", legacyFilenames = { ".ruby-version" } }
+function PLUGIN:Available(ctx) return {} end
+function PLUGIN:PreInstall(ctx) return {} end
+function PLUGIN:EnvKeys(ctx) return {} end
+`,
+		filepath.Join(project, ".nvmrc"):          "lts/20.1.0\n",
+		filepath.Join(workDir, ".python-version"): "# comment\nv3.11.4\n",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	manager := &Manager{
+		PathMeta: &PathMeta{
+			PluginPath:       pluginDir,
+			WorkingDirectory: workDir,
+		},
+		Config: &config.Config{
+			Proxy:   config.EmptyProxy,
+			Project: config.EmptyProject,
+		},
+		openSdks: make(map[string]*Sdk),
+	}
+	defer func() {
+		for _, sdk := range manager.openSdks {
+			sdk.Close()
+		}
+	}()
+	legacy := manager.loadLegacyRecord()
+	versions := legacy.Export()
+	if versions["nodejs"] != "20.1.0" || versions["python"] != "3.11.4" {
+		t.Errorf("unexpected legacy versions: %v", versions)
+	}
+	if origin := legacy.Origin("nodejs"); origin != filepath.Join(project, ".nvmrc") {
+		t.Errorf("unexpected origin: %s", origin)
+	}
+	// The legacy filenames are cached, a plugin without a legacy file is not loaded again
+	for _, sdk := range manager.openSdks {
+		sdk.Close()
+	}
+	manager.openSdks = make(map[string]*Sdk)
+	if versions = manager.loadLegacyRecord().Export(); versions["nodejs"] != "20.1.0" {
+		t.Errorf("unexpected legacy versions: %v", versions)
+	}
+	if _, ok := manager.openSdks["ruby"]; ok {
+		t.Error("expected the ruby plugin not to be loaded")
+	}
+
+	// .tool-versions has higher priority
+	if err := os.WriteFile(filepath.Join(workDir, ".tool-versions"), []byte("nodejs 21.0.0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	record, err := env.NewProjectRecord(workDir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	merged := env.NewMultiRecord(legacy, record).Export()
+	if merged["nodejs"] != "21.0.0" || merged["python"] != "3.11.4" {
+		t.Errorf("unexpected merged versions: %v", merged)
+	}
+}
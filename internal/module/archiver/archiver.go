@@ -0,0 +1,118 @@
+/*
+ *    Copyright 2024 Han Li and contributors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package archiver
+
+import (
+	"errors"
+
+	"github.com/version-fox/vfox/internal/module/file"
+	"github.com/version-fox/vfox/internal/util"
+	lua "github.com/yuin/gopher-lua"
+)
+
+// parseOptions reads the options of an archive:
+//
+//	{
+//	    format = "tar.gz", -- one of tar.gz, tgz, tar.xz, tar and zip, detected from the file extension by default
+//	    strip = 2,         -- the number of leading path components to be removed, the top-level directory by default
+//	}
+func parseOptions(L *lua.LState, n int) *util.DecompressOptions {
+	opts := &util.DecompressOptions{Strip: util.DefaultStrip}
+	table := L.OptTable(n, nil)
+	if table == nil {
+		return opts
+	}
+	if format, ok := table.RawGetString("format").(lua.LString); ok {
+		opts.Format = string(format)
+	}
+	if strip, ok := table.RawGetString("strip").(lua.LNumber); ok {
+		opts.Strip = int(strip)
+	}
+	return opts
+}
+
+func decompressor(src string, opts *util.DecompressOptions) (util.Decompressor, error) {
+	d, err := util.NewDecompressorWithOptions(src, opts)
+	if err != nil {
+		return nil, err
+	}
+	if d == nil {
+		return nil, errors.New("unknown archive format of " + src + ", please specify the format")
+	}
+	return d, nil
+}
+
+// Decompress unpacks the archive into dest, it returns an error message if failed.
+// In PostInstall dest is confined to the install path of the version, like the file module.
+//
+//	local archiver = require("archiver")
+//	local err = archiver.decompress(ctx.rootPath .. "/jdk.tar.gz", ctx.rootPath, { strip = 2 })
+func Decompress(L *lua.LState) int {
+	src := L.CheckString(1)
+	dest, err := file.Resolve(L, L.CheckString(2))
+	var d util.Decompressor
+	if err == nil {
+		d, err = decompressor(src, parseOptions(L, 3))
+	}
+	if err == nil {
+		err = d.Decompress(dest)
+	}
+	if err != nil {
+		L.Push(lua.LString(err.Error()))
+		return 1
+	}
+	L.Push(lua.LNil)
+	return 1
+}
+
+// List returns the names of the entries in the archive, or nil and an error message if failed.
+//
+//	local names, err = archiver.list(ctx.rootPath .. "/jdk.tar.gz")
+func List(L *lua.LState) int {
+	src := L.CheckString(1)
+	d, err := decompressor(src, parseOptions(L, 2))
+	var names []string
+	if err == nil {
+		names, err = d.List()
+	}
+	if err != nil {
+		L.Push(lua.LNil)
+		L.Push(lua.LString(err.Error()))
+		return 2
+	}
+	result := L.NewTable()
+	for _, name := range names {
+		result.Append(lua.LString(name))
+	}
+	L.Push(result)
+	return 1
+}
+
+func loader(L *lua.LState) int {
+	t := L.NewTable()
+	L.SetFuncs(t, map[string]lua.LGFunction{
+		"decompress": Decompress,
+		"list":       List,
+	})
+	L.Push(t)
+	return 1
+}
+
+// Preload registers the archiver module.
+func Preload(L *lua.LState) {
+	L.PreloadModule("archiver", loader)
+}
@@ -0,0 +1,88 @@
+/*
+ *    Copyright 2024 Han Li and contributors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package archiver
+
+import (
+	"archive/zip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/version-fox/vfox/internal/module/file"
+	lua "github.com/yuin/gopher-lua"
+)
+
+func TestArchiver(t *testing.T) {
+	dir := t.TempDir()
+	// The archive has no known extension, such as an archive inside another archive.
+	src := filepath.Join(dir, "sdk.pkg")
+	f, err := os.Create(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	for _, name := range []string{"sdk/lib/a.txt", "sdk/lib/b.txt"} {
+		w, _ := zw.Create(name)
+		_, _ = w.Write([]byte(name))
+	}
+	_ = zw.Close()
+	_ = f.Close()
+
+	dest := filepath.Join(dir, "dest")
+	str := fmt.Sprintf(`
+	local archiver = require("archiver")
+	local src, dest = %q, %q
+	local err = archiver.decompress(src, dest)
+	assert(err ~= nil and string.find(err, "specify the format"), err)
+	err = archiver.decompress(src, dest, { format = "zip", strip = 2 })
+	assert(err == nil, err)
+	local names, err = archiver.list(src, { format = "zip" })
+	assert(err == nil, err)
+	assert(#names == 2 and names[1] == "sdk/lib/a.txt", #names)
+	names, err = archiver.list(src .. ".missing", { format = "zip" })
+	assert(names == nil and err ~= nil)
+	`, src, dest)
+	s := lua.NewState()
+	defer s.Close()
+	Preload(s)
+	if err = s.DoString(str); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if _, err = os.Stat(filepath.Join(dest, name)); err != nil {
+			t.Errorf("expected %s to be decompressed with strip 2, err: %s", name, err)
+		}
+	}
+}
+
+func TestDecompressConfined(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "root")
+	s := lua.NewState()
+	defer s.Close()
+	Preload(s)
+	file.Bind(s, root)
+	str := `
+	local archiver = require("archiver")
+	local err = archiver.decompress("sdk.zip", "../dest")
+	assert(err ~= nil and string.find(err, "outside"), err)
+	`
+	if err := s.DoString(str); err != nil {
+		t.Fatal(err)
+	}
+}
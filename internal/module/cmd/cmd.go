@@ -0,0 +1,176 @@
+/*
+ *    Copyright 2024 Han Li and contributors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/version-fox/vfox/internal/logger"
+	lua "github.com/yuin/gopher-lua"
+)
+
+// logKey is the key of the log writer in the registry of the state, see Bind.
+const logKey = "__vfox_cmd_log"
+
+// Bind records the commands run by the state and their output into the writer, nil stops recording.
+// The commands are killed once the context of the state is canceled, see lua.LState.SetContext.
+func Bind(L *lua.LState, log io.Writer) {
+	if log == nil {
+		L.G.Registry.RawSetString(logKey, lua.LNil)
+		return
+	}
+	ud := L.NewUserData()
+	ud.Value = &syncWriter{w: log}
+	L.G.Registry.RawSetString(logKey, ud)
+}
+
+// syncWriter serializes the writes of stdout and stderr into the log.
+type syncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (s *syncWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Write(p)
+}
+
+// debugWriter streams the output to the logger if the debug mode is on.
+type debugWriter struct{}
+
+func (debugWriter) Write(p []byte) (int, error) {
+	logger.Debugf("%s", p)
+	return len(p), nil
+}
+
+func log(L *lua.LState) io.Writer {
+	if ud, ok := L.G.Registry.RawGetString(logKey).(*lua.LUserData); ok {
+		if w, ok := ud.Value.(io.Writer); ok {
+			return w
+		}
+	}
+	return io.Discard
+}
+
+// Exec runs a command and waits for it to exit. A non-zero exit code is not an error,
+// the error is returned only if the command can not be started, times out or is canceled.
+//
+//	local cmd = require("cmd")
+//	local result, err = cmd.exec({ "gem", "install", "bundler" }, {
+//	    cwd = ctx.rootPath,
+//	    env = { GEM_HOME = ctx.rootPath },
+//	    timeout = 300, -- in seconds, no timeout by default
+//	})
+//
+//	result : {
+//	    stdout = "",
+//	    stderr = "",
+//	    exit_code = 0
+//	}
+func Exec(L *lua.LState) int {
+	argsTable := L.CheckTable(1)
+	var args []string
+	for i := 1; i <= argsTable.Len(); i++ {
+		args = append(args, argsTable.RawGetInt(i).String())
+	}
+	if len(args) == 0 || args[0] == "" {
+		L.ArgError(1, "command expected")
+		return 0
+	}
+	opts := L.OptTable(2, L.NewTable())
+
+	ctx := L.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if timeout, ok := opts.RawGetString("timeout").(lua.LNumber); ok && timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(float64(timeout)*float64(time.Second)))
+		defer cancel()
+	}
+	c := exec.CommandContext(ctx, args[0], args[1:]...)
+	if ctx.Done() != nil {
+		killGroup(c)
+		// Do not wait for the children which still hold the output after being killed.
+		c.WaitDelay = time.Second
+	}
+	if cwd, ok := opts.RawGetString("cwd").(lua.LString); ok {
+		c.Dir = string(cwd)
+	}
+	if envTable, ok := opts.RawGetString("env").(*lua.LTable); ok {
+		c.Env = os.Environ()
+		envTable.ForEach(func(key lua.LValue, value lua.LValue) {
+			c.Env = append(c.Env, key.String()+"="+value.String())
+		})
+	}
+
+	logWriter := log(L)
+	_, _ = fmt.Fprintf(logWriter, "$ %s\n", strings.Join(args, " "))
+	logger.Debugf("$ %s\n", strings.Join(args, " "))
+	var stdout, stderr bytes.Buffer
+	c.Stdout = io.MultiWriter(&stdout, logWriter, debugWriter{})
+	c.Stderr = io.MultiWriter(&stderr, logWriter, debugWriter{})
+
+	err := c.Run()
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		L.Push(lua.LNil)
+		L.Push(lua.LString(err.Error()))
+		return 2
+	}
+	exitCode := c.ProcessState.ExitCode()
+	_, _ = fmt.Fprintf(logWriter, "exit code: %d\n", exitCode)
+	result := L.NewTable()
+	L.SetField(result, "stdout", lua.LString(stdout.String()))
+	L.SetField(result, "stderr", lua.LString(stderr.String()))
+	L.SetField(result, "exit_code", lua.LNumber(exitCode))
+	L.Push(result)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		// The command has been killed, the output so far is returned along with the error.
+		if errors.Is(ctxErr, context.DeadlineExceeded) {
+			L.Push(lua.LString("command timed out: " + strings.Join(args, " ")))
+		} else {
+			L.Push(lua.LString("command canceled: " + strings.Join(args, " ")))
+		}
+		return 2
+	}
+	return 1
+}
+
+func loader(L *lua.LState) int {
+	t := L.NewTable()
+	L.SetFuncs(t, map[string]lua.LGFunction{
+		"exec": Exec,
+	})
+	L.Push(t)
+	return 1
+}
+
+// Preload registers the cmd module.
+func Preload(L *lua.LState) {
+	L.PreloadModule("cmd", loader)
+}
@@ -0,0 +1,81 @@
+/*
+ *    Copyright 2024 Han Li and contributors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+func TestExec(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("sh is required")
+	}
+	dir := t.TempDir()
+	str := `
+	local cmd = require("cmd")
+	local result, err = cmd.exec({ "sh", "-c", "echo out; echo err >&2; pwd; echo $FOO; exit 3" }, {
+		cwd = "` + dir + `",
+		env = { FOO = "bar" },
+	})
+	assert(err == nil, err)
+	assert(result.exit_code == 3, result.exit_code)
+	assert(result.stdout == "out\n` + dir + `\nbar\n", result.stdout)
+	assert(result.stderr == "err\n", result.stderr)
+	result, err = cmd.exec({ "sh", "-c", "sleep 5" }, { timeout = 0.1 })
+	assert(result.exit_code ~= 0 and string.find(err, "timed out"), err)
+	result, err = cmd.exec({ "vfox-command-not-found" })
+	assert(result == nil and err ~= nil)
+	`
+	s := lua.NewState()
+	defer s.Close()
+	Preload(s)
+	var log bytes.Buffer
+	Bind(s, &log)
+	if err := s.DoString(str); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(log.String(), "$ sh -c echo out;") || !strings.Contains(log.String(), "exit code: 3") {
+		t.Errorf("unexpected log: %s", log.String())
+	}
+}
+
+func TestExecCanceled(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("sh is required")
+	}
+	s := lua.NewState()
+	defer s.Close()
+	Preload(s)
+	ctx, cancel := context.WithCancel(context.Background())
+	s.SetContext(ctx)
+	time.AfterFunc(100*time.Millisecond, cancel)
+	start := time.Now()
+	err := s.DoString(`require("cmd").exec({ "sh", "-c", "sleep 5" })`)
+	if err == nil {
+		t.Error("expected the canceled state to fail")
+	}
+	if time.Since(start) > 3*time.Second {
+		t.Errorf("expected the command to be killed, took %s", time.Since(start))
+	}
+}
@@ -14,36 +14,309 @@
  *    limitations under the License.
  */
 
-package html
+package file
 
 import (
-	lua "github.com/yuin/gopher-lua"
+	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+
+	lua "github.com/yuin/gopher-lua"
 )
 
-const luaFileTypeName = "file_operation"
+// rootKey is the key of the root path in the registry of the state, see Bind.
+const rootKey = "__vfox_file_root"
 
-type FileOperation struct {
-	rootPath string
+// FileOperation provides the file functions to the plugins. Every path is confined to the root,
+// which is bound to the install path of the version during PostInstall. A relative path is relative to the root.
+type FileOperation struct{}
+
+// Bind confines the file module of the state to the root, an empty root disables the module.
+func Bind(L *lua.LState, root string) {
+	if root == "" {
+		L.G.Registry.RawSetString(rootKey, lua.LNil)
+		return
+	}
+	L.G.Registry.RawSetString(rootKey, lua.LString(filepath.Clean(root)))
 }
 
-func (f *FileOperation) symlink(L *lua.LState) int {
-	src := L.CheckString(1)
-	dest := L.CheckString(2)
-	// TODO check
-	err := os.Symlink(filepath.Join(f.rootPath, src), filepath.Join(f.rootPath, dest))
+func boundRoot(L *lua.LState) (string, bool) {
+	root, ok := L.G.Registry.RawGetString(rootKey).(lua.LString)
+	return string(root), ok && root != ""
+}
+
+func (f *FileOperation) root(L *lua.LState) string {
+	root, ok := boundRoot(L)
+	if !ok {
+		L.RaiseError("the file module is only available in PostInstall")
+	}
+	return root
+}
+
+// Resolve returns the absolute path for the other modules writing to the disk, it returns an error
+// if the path is outside the root bound to the state, including through a symbolic link.
+// The path is returned as is if no root is bound, that is outside PostInstall.
+func Resolve(L *lua.LState, path string) (string, error) {
+	root, ok := boundRoot(L)
+	if !ok {
+		return path, nil
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(root, path)
+	}
+	path = filepath.Clean(path)
+	if !confined(root, path) {
+		return "", fmt.Errorf("%s is outside of %s", path, root)
+	}
+	return path, nil
+}
+
+// resolve returns the absolute path, it raises an error if the path is outside the root,
+// including through a symbolic link.
+func (f *FileOperation) resolve(L *lua.LState, path string) string {
+	f.root(L)
+	path, err := Resolve(L, path)
+	raise(L, err)
+	return path
+}
+
+// confined reports whether the path is within the root, both lexically and after resolving
+// the symbolic links.
+func confined(root, path string) bool {
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		realRoot = root
+	}
+	return within(root, path) && within(realRoot, realPath(path))
+}
+
+// realPath resolves the symbolic links of the deepest existing parent of the path.
+func realPath(path string) string {
+	var rest []string
+	for {
+		if real, err := filepath.EvalSymlinks(path); err == nil {
+			return filepath.Join(append([]string{real}, rest...)...)
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return filepath.Join(append([]string{path}, rest...)...)
+		}
+		rest = append([]string{filepath.Base(path)}, rest...)
+		path = parent
+	}
+}
+
+func within(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func raise(L *lua.LState, err error) {
 	if err != nil {
-		L.RaiseError(err.Error())
-		return 0
+		L.RaiseError("%s", err.Error())
+	}
+}
+
+// symlink creates a symbolic link at dest to src.
+// file.symlink("bin/node", "node") return true
+func (f *FileOperation) symlink(L *lua.LState) int {
+	src := f.resolve(L, L.CheckString(1))
+	dest := f.resolve(L, L.CheckString(2))
+	raise(L, os.Symlink(src, dest))
+	L.Push(lua.LTrue)
+	return 1
+}
+
+// read returns the content of the file.
+// file.read("VERSION") return content
+func (f *FileOperation) read(L *lua.LState) int {
+	content, err := os.ReadFile(f.resolve(L, L.CheckString(1)))
+	raise(L, err)
+	L.Push(lua.LString(content))
+	return 1
+}
+
+// write writes the content to the file, the file is created or truncated.
+// file.write("bin/env.sh", "export FOO=bar") return true
+func (f *FileOperation) write(L *lua.LState) int {
+	path := f.resolve(L, L.CheckString(1))
+	content := L.CheckString(2)
+	raise(L, os.WriteFile(path, []byte(content), 0644))
+	L.Push(lua.LTrue)
+	return 1
+}
+
+// exists reports whether the path exists.
+// file.exists("bin/node") return boolean
+func (f *FileOperation) exists(L *lua.LState) int {
+	_, err := os.Lstat(f.resolve(L, L.CheckString(1)))
+	L.Push(lua.LBool(err == nil))
+	return 1
+}
+
+// mkdir creates the directory along with the parents.
+// file.mkdir("lib/cache") return true
+func (f *FileOperation) mkdir(L *lua.LState) int {
+	raise(L, os.MkdirAll(f.resolve(L, L.CheckString(1)), 0755))
+	L.Push(lua.LTrue)
+	return 1
+}
+
+// remove removes the path and any children it contains, the root itself can not be removed.
+// file.remove("docs") return true
+func (f *FileOperation) remove(L *lua.LState) int {
+	path := f.resolve(L, L.CheckString(1))
+	if path == f.root(L) {
+		L.RaiseError("can not remove the root %s", path)
 	}
+	raise(L, os.RemoveAll(path))
+	L.Push(lua.LTrue)
+	return 1
+}
+
+// chmod changes the mode of the file, the mode is either a number or an octal string.
+// file.chmod("bin/node", "755") return true
+func (f *FileOperation) chmod(L *lua.LState) int {
+	path := f.resolve(L, L.CheckString(1))
+	var mode uint64
+	switch value := L.CheckAny(2).(type) {
+	case lua.LNumber:
+		mode = uint64(value)
+	case lua.LString:
+		var err error
+		if mode, err = strconv.ParseUint(string(value), 8, 32); err != nil {
+			L.ArgError(2, "invalid mode: "+string(value))
+		}
+	default:
+		L.ArgError(2, "mode expected")
+	}
+	raise(L, os.Chmod(path, fs.FileMode(mode)&fs.ModePerm))
+	L.Push(lua.LTrue)
+	return 1
+}
+
+// glob returns the absolute paths matching the pattern, the matches escaping the root through
+// a symbolic link are dropped.
+// file.glob("bin/*") return table
+func (f *FileOperation) glob(L *lua.LState) int {
+	root := f.root(L)
+	pattern := L.CheckString(1)
+	if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(root, pattern)
+	}
+	if !within(root, filepath.Clean(pattern)) {
+		L.RaiseError("%s is outside of %s", pattern, root)
+	}
+	matches, err := filepath.Glob(pattern)
+	raise(L, err)
+	result := L.NewTable()
+	for _, match := range matches {
+		if confined(root, match) {
+			result.Append(lua.LString(match))
+		}
+	}
+	L.Push(result)
+	return 1
+}
+
+// copy copies the file, or the directory recursively, to dest.
+// file.copy("bin/node", "bin/nodejs") return true
+func (f *FileOperation) copy(L *lua.LState) int {
+	src := f.resolve(L, L.CheckString(1))
+	dest := f.resolve(L, L.CheckString(2))
+	raise(L, copyPath(src, dest))
+	L.Push(lua.LTrue)
+	return 1
+}
+
+// rename renames or moves the path to dest.
+// file.rename("node-v20.0.0", "node") return true
+func (f *FileOperation) rename(L *lua.LState) int {
+	src := f.resolve(L, L.CheckString(1))
+	dest := f.resolve(L, L.CheckString(2))
+	raise(L, os.Rename(src, dest))
 	L.Push(lua.LTrue)
 	return 1
 }
 
+// join joins the elements into a path with the separator of the os, it is not confined to the root.
+// file.join("bin", "node") return "bin/node"
+func (f *FileOperation) join(L *lua.LState) int {
+	var elems []string
+	for i := 1; i <= L.GetTop(); i++ {
+		elems = append(elems, L.CheckString(i))
+	}
+	L.Push(lua.LString(filepath.Join(elems...)))
+	return 1
+}
+
+func copyPath(src, dest string) error {
+	info, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+	switch {
+	case info.Mode()&fs.ModeSymlink != 0:
+		target, err := os.Readlink(src)
+		if err != nil {
+			return err
+		}
+		return os.Symlink(target, dest)
+	case info.IsDir():
+		if err = os.MkdirAll(dest, info.Mode().Perm()); err != nil {
+			return err
+		}
+		entries, err := os.ReadDir(src)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err = copyPath(filepath.Join(src, entry.Name()), filepath.Join(dest, entry.Name())); err != nil {
+				return err
+			}
+		}
+		return nil
+	case info.Mode().IsRegular():
+		return copyFile(src, dest, info.Mode().Perm())
+	default:
+		return fmt.Errorf("can not copy %s, unsupported file type", src)
+	}
+}
+
+func copyFile(src, dest string, mode fs.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	if _, err = io.Copy(out, in); err != nil {
+		return err
+	}
+	return out.Close()
+}
+
 func (f *FileOperation) luaMap() map[string]lua.LGFunction {
 	return map[string]lua.LGFunction{
 		"symlink": f.symlink,
+		"read":    f.read,
+		"write":   f.write,
+		"exists":  f.exists,
+		"mkdir":   f.mkdir,
+		"remove":  f.remove,
+		"chmod":   f.chmod,
+		"glob":    f.glob,
+		"copy":    f.copy,
+		"rename":  f.rename,
+		"join":    f.join,
 	}
 }
 
@@ -54,7 +327,8 @@ func (f *FileOperation) loader(L *lua.LState) int {
 	return 1
 }
 
-func Preload(L *lua.LState, rootPath string) {
-	operation := &FileOperation{rootPath: rootPath}
+// Preload registers the file module, which is disabled until a root is bound, see Bind.
+func Preload(L *lua.LState) {
+	operation := &FileOperation{}
 	L.PreloadModule("file", operation.loader)
 }
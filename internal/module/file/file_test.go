@@ -14,11 +14,16 @@
  *    limitations under the License.
  */
 
-package html
+package file
 
 import (
-	lua "github.com/yuin/gopher-lua"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
 	"testing"
+
+	lua "github.com/yuin/gopher-lua"
 )
 
 func TestRequire(t *testing.T) {
@@ -27,44 +32,96 @@ func TestRequire(t *testing.T) {
 	assert(type(file) == "table")
 	assert(type(file.symlink) == "function")
 	`
-	evalLua(str, t)
+	evalLua(str, "", t)
 }
 
-func TestFind(t *testing.T) {
-	const str = `	
-	local file = require("file")
-	file.symlink(src, dest)
-	assert(div:text() == "hello world")
-	`
-	evalLua(str, t)
+func TestUnbound(t *testing.T) {
+	s := lua.NewState()
+	defer s.Close()
+	Preload(s)
+	if err := s.DoString(`require("file").read("VERSION")`); err == nil {
+		t.Error("expected an error without a root")
+	}
 }
 
-func TestFirst(t *testing.T) {
-	const str = `	
-	local html = require("html")
-	local doc = html.parse("<html><body><div id='test'>123</div><div id='test'>456</div></body></html>")
-	local div = doc:find("div")
-	assert(div:first():text() == "123")
+func TestOperations(t *testing.T) {
+	root := t.TempDir()
+	const str = `
+	local file = require("file")
+	assert(file.exists("bin") == false)
+	file.mkdir("bin/sub")
+	assert(file.exists("bin/sub"))
+	file.write("bin/node", "#!/bin/sh")
+	assert(file.read("bin/node") == "#!/bin/sh")
+	file.chmod("bin/node", "755")
+	file.copy("bin", "bin2")
+	assert(file.read("bin2/node") == "#!/bin/sh")
+	file.rename("bin2", "bin3")
+	assert(file.exists("bin2") == false and file.exists("bin3/sub"))
+	file.symlink("bin/node", "node")
+	assert(file.read("node") == "#!/bin/sh")
+	local matches = file.glob("bin*/node")
+	assert(#matches == 2, #matches)
+	file.remove("bin3")
+	assert(file.exists("bin3") == false)
+	assert(file.join("a", "b") == "a" .. package.config:sub(1, 1) .. "b")
 	`
-	evalLua(str, t)
+	evalLua(str, root, t)
+	if runtime.GOOS == "windows" {
+		return
+	}
+	info, err := os.Stat(filepath.Join(root, "bin", "node"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0755 {
+		t.Errorf("expected mode 0755, got %o", info.Mode().Perm())
+	}
 }
 
-func TestContinuousFind(t *testing.T) {
-	const str = `
-	local html = require("html")
-	local doc = html.parse("<html><body><div id='test'>test</div><div id='t2'>456</div></body></html>")
-	local div = doc:find("body"):find("#t2")
-	print(div:text() == "456")
-	`
-	evalLua(str, t)
+func TestConfined(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "root")
+	if err := os.MkdirAll(root, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(dir, filepath.Join(root, "escape")); err != nil {
+		t.Skip("symlink is not supported")
+	}
+	for _, code := range []string{
+		`file.read("../secret")`,
+		fmt.Sprintf(`file.write(%q, "")`, filepath.Join(dir, "secret")),
+		`file.write("escape/secret", "")`,
+		`file.copy("../secret", "secret")`,
+		`file.glob("../*")`,
+		`file.remove(".")`,
+	} {
+		s := lua.NewState()
+		Preload(s)
+		Bind(s, root)
+		if err := s.DoString(`local file = require("file")` + "\n" + code); err == nil {
+			t.Errorf("expected %s to fail", code)
+		}
+		s.Close()
+	}
+	if _, err := os.Stat(filepath.Join(dir, "secret")); !os.IsNotExist(err) {
+		t.Errorf("expected no file outside of the root, got %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "outside"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	evalLua(`local file = require("file")
+	local matches = file.glob("escape/*")
+	assert(#matches == 1 and matches[1]:sub(-4) == "root", "expected only the root through the link")
+	`, root, t)
 }
 
-func evalLua(str string, t *testing.T) {
+func evalLua(str, root string, t *testing.T) {
 	s := lua.NewState()
 	defer s.Close()
-	Preload(s, "")
+	Preload(s)
+	Bind(s, root)
 	if err := s.DoString(str); err != nil {
 		t.Error(err)
 	}
-
 }
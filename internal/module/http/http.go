@@ -17,11 +17,28 @@
 package http
 
 import (
-	"github.com/version-fox/vfox/internal/config"
-	lua "github.com/yuin/gopher-lua"
+	"context"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/version-fox/vfox/internal/config"
+	"github.com/version-fox/vfox/internal/module/file"
+	"github.com/version-fox/vfox/internal/printer"
+	lua "github.com/yuin/gopher-lua"
+)
+
+const (
+	// headerTimeout limits connecting and waiting for the response headers, while reading
+	// the body may take any time unless the timeout option is set.
+	headerTimeout     = 30 * time.Second
+	defaultRetryDelay = time.Second
 )
 
 type Module struct {
@@ -29,136 +46,317 @@ type Module struct {
 	client *http.Client
 }
 
-// Get performs a http get request
-// @param url string
-// @param headers table
-// @return resp table
-// @return err string
-// local http = require("http")
-//
-//	http.get({
-//	    url = "http://ip.jsontest.com/"
-//	}) return (response, error)
+// requestOptions are parsed from the table passed to the functions of the module:
 //
-//	response : {
-//	    body = "",
-//	    status_code = 200,
-//	    headers = table
+//	{
+//	    url = "https://example.com",  -- required
+//	    method = "POST",              -- only for http.request, default GET
+//	    headers = { ["Content-Type"] = "application/json" },
+//	    body = "{}",
+//	    timeout = 10,                 -- in seconds, limits the whole request including the body
+//	    follow_redirects = false,     -- default true
+//	    retry = 3,                    -- retries on network errors, 429 and 5xx
+//	    retry_delay = 1,              -- in seconds, doubled after each retry
 //	}
-func (m *Module) Get(L *lua.LState) int {
-	param := L.CheckTable(1)
-	urlStr := param.RawGetString("url")
-	if urlStr == lua.LNil {
-		L.Push(lua.LNil)
-		L.Push(lua.LString("url is required"))
-	}
+type requestOptions struct {
+	method          string
+	url             string
+	headers         http.Header
+	body            string
+	timeout         time.Duration
+	followRedirects bool
+	retry           int
+	retryDelay      time.Duration
+}
 
-	req, err := http.NewRequest("GET", urlStr.String(), nil)
-	if err != nil {
-		L.Push(lua.LNil)
-		L.Push(lua.LString(err.Error()))
-		return 2
+func parseOptions(param *lua.LTable, method string) (*requestOptions, error) {
+	opts := &requestOptions{
+		method:          method,
+		headers:         make(http.Header),
+		followRedirects: true,
+		retryDelay:      defaultRetryDelay,
+	}
+	urlStr, ok := param.RawGetString("url").(lua.LString)
+	if !ok || urlStr == "" {
+		return nil, errors.New("url is required")
+	}
+	opts.url = string(urlStr)
+	if method == "" {
+		opts.method = http.MethodGet
+		if m, ok := param.RawGetString("method").(lua.LString); ok && m != "" {
+			opts.method = strings.ToUpper(string(m))
+		}
+	}
+	if table, ok := param.RawGetString("headers").(*lua.LTable); ok {
+		table.ForEach(func(key lua.LValue, value lua.LValue) {
+			opts.headers.Add(key.String(), value.String())
+		})
 	}
+	if body, ok := param.RawGetString("body").(lua.LString); ok {
+		opts.body = string(body)
+	}
+	if t, ok := param.RawGetString("timeout").(lua.LNumber); ok {
+		opts.timeout = time.Duration(float64(t) * float64(time.Second))
+	}
+	if follow, ok := param.RawGetString("follow_redirects").(lua.LBool); ok {
+		opts.followRedirects = bool(follow)
+	}
+	if retry, ok := param.RawGetString("retry").(lua.LNumber); ok {
+		opts.retry = int(retry)
+	}
+	if delay, ok := param.RawGetString("retry_delay").(lua.LNumber); ok {
+		opts.retryDelay = time.Duration(float64(delay) * float64(time.Second))
+	}
+	if opts.timeout < 0 || opts.retry < 0 || opts.retryDelay < 0 {
+		return nil, errors.New("timeout, retry and retry_delay must not be negative")
+	}
+	return opts, nil
+}
 
-	headersTable := param.RawGetString("headers")
-	if headersTable != lua.LNil {
-		if table, ok := headersTable.(*lua.LTable); ok {
-			table.ForEach(func(key lua.LValue, value lua.LValue) {
-				req.Header.Add(key.String(), value.String())
-			})
+// do sends the request, and sends it again with a backoff if it fails temporarily.
+// The returned cancel func must be called after the body of the response is read.
+func (m *Module) do(opts *requestOptions) (*http.Response, context.CancelFunc, error) {
+	client := *m.client
+	if !opts.followRedirects {
+		client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
 		}
 	}
-	resp, err := m.client.Do(req)
-	if err != nil {
-		L.Push(lua.LNil)
-		L.Push(lua.LString(err.Error()))
-		return 2
+	delay := opts.retryDelay
+	for attempt := 0; ; attempt++ {
+		ctx, cancel := context.Background(), context.CancelFunc(func() {})
+		if opts.timeout > 0 {
+			ctx, cancel = context.WithTimeout(ctx, opts.timeout)
+		}
+		var body io.Reader
+		if opts.body != "" {
+			body = strings.NewReader(opts.body)
+		}
+		req, err := http.NewRequestWithContext(ctx, opts.method, opts.url, body)
+		if err != nil {
+			cancel()
+			return nil, nil, err
+		}
+		req.Header = opts.headers.Clone()
+		resp, err := client.Do(req)
+		if attempt >= opts.retry || (err == nil && !retryable(resp.StatusCode)) {
+			if err != nil {
+				cancel()
+				return nil, nil, err
+			}
+			return resp, cancel, nil
+		}
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+		cancel()
+		time.Sleep(delay)
+		delay *= 2
 	}
-	defer resp.Body.Close()
+}
+
+func retryable(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode >= http.StatusInternalServerError
+}
+
+func headersTable(L *lua.LState, header http.Header) *lua.LTable {
 	headers := L.NewTable()
-	for k, v := range resp.Header {
+	for k, v := range header {
 		if len(v) > 0 {
 			headers.RawSetString(k, lua.LString(v[0]))
 		}
 	}
-	body, err := io.ReadAll(resp.Body)
+	return headers
+}
+
+// request performs a request with the method, or the method in the options if it is empty.
+func (m *Module) request(L *lua.LState, method string) int {
+	param := L.CheckTable(1)
+	opts, err := parseOptions(param, method)
 	if err != nil {
 		L.Push(lua.LNil)
 		L.Push(lua.LString(err.Error()))
 		return 2
 	}
+	resp, cancel, err := m.do(opts)
+	if err != nil {
+		L.Push(lua.LNil)
+		L.Push(lua.LString(err.Error()))
+		return 2
+	}
+	defer cancel()
+	defer resp.Body.Close()
 	result := L.NewTable()
-	L.SetField(result, "body", lua.LString(body))
+	if opts.method != http.MethodHead {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			L.Push(lua.LNil)
+			L.Push(lua.LString(err.Error()))
+			return 2
+		}
+		L.SetField(result, "body", lua.LString(body))
+	}
 	L.SetField(result, "status_code", lua.LNumber(resp.StatusCode))
-	L.SetField(result, "headers", headers)
+	L.SetField(result, "headers", headersTable(L, resp.Header))
 	L.SetField(result, "content_length", lua.LNumber(resp.ContentLength))
 	L.Push(result)
 	return 1
 }
 
+// Get performs a http get request
+// @param url string
+// @param headers table
+// @return resp table
+// @return err string
+// local http = require("http")
+//
+//	http.get({
+//	    url = "http://ip.jsontest.com/"
+//	}) return (response, error)
+//
+//	response : {
+//	    body = "",
+//	    status_code = 200,
+//	    headers = table
+//	}
+func (m *Module) Get(L *lua.LState) int {
+	return m.request(L, http.MethodGet)
+}
+
+// Head performs a http head request, the response has no body.
 func (m *Module) Head(L *lua.LState) int {
-	param := L.CheckTable(1)
-	urlStr := param.RawGetString("url")
-	if urlStr == lua.LNil {
-		L.Push(lua.LNil)
-		L.Push(lua.LString("url is required"))
-	}
+	return m.request(L, http.MethodHead)
+}
+
+// Post performs a http post request with the body of the options.
+//
+//	http.post({
+//	    url = "https://example.com/api",
+//	    headers = { ["Content-Type"] = "application/json" },
+//	    body = '{"name": "vfox"}'
+//	}) return (response, error)
+func (m *Module) Post(L *lua.LState) int {
+	return m.request(L, http.MethodPost)
+}
+
+// Put performs a http put request with the body of the options.
+func (m *Module) Put(L *lua.LState) int {
+	return m.request(L, http.MethodPut)
+}
+
+// Delete performs a http delete request.
+func (m *Module) Delete(L *lua.LState) int {
+	return m.request(L, http.MethodDelete)
+}
+
+// Request performs a http request with any method, which is GET by default.
+//
+//	http.request({
+//	    method = "PATCH",
+//	    url = "https://example.com/api",
+//	    body = "..."
+//	}) return (response, error)
+func (m *Module) Request(L *lua.LState) int {
+	return m.request(L, "")
+}
 
-	req, err := http.NewRequest("HEAD", urlStr.String(), nil)
+// DownloadFile downloads a file to the path and shows the progress, the file is streamed to the disk
+// instead of being read into memory. The first argument is either the url or the options of the request.
+// Like the other requests, only connecting and waiting for the response headers are limited by default.
+// In PostInstall the path is confined to the install path of the version, like the file module.
+//
+//	http.download_file("https://example.com/file.tar.gz", "/path/to/file.tar.gz") return error
+//	http.download_file({ url = "https://example.com/file.tar.gz", headers = {} }, "/path/to/file.tar.gz") return error
+func (m *Module) DownloadFile(L *lua.LState) int {
+	var param *lua.LTable
+	switch arg := L.CheckAny(1).(type) {
+	case lua.LString:
+		param = L.NewTable()
+		param.RawSetString("url", arg)
+	case *lua.LTable:
+		param = arg
+	default:
+		L.ArgError(1, "url or options expected")
+		return 0
+	}
+	path, err := file.Resolve(L, L.CheckString(2))
 	if err != nil {
-		L.Push(lua.LNil)
 		L.Push(lua.LString(err.Error()))
-		return 2
-	}
-	headersTable := param.RawGetString("headers")
-	if headersTable != lua.LNil {
-		if table, ok := headersTable.(*lua.LTable); ok {
-			table.ForEach(func(key lua.LValue, value lua.LValue) {
-				req.Header.Add(key.String(), value.String())
-			})
-		}
+		return 1
 	}
-	resp, err := m.client.Do(req)
+	opts, err := parseOptions(param, http.MethodGet)
 	if err != nil {
-		L.Push(lua.LNil)
 		L.Push(lua.LString(err.Error()))
-		return 2
+		return 1
 	}
-	headers := L.NewTable()
-	for k, v := range resp.Header {
-		if len(v) > 0 {
-			headers.RawSetString(k, lua.LString(v[0]))
-		}
+	if err = m.downloadFile(opts, path); err != nil {
+		L.Push(lua.LString(err.Error()))
+		return 1
 	}
-	result := L.NewTable()
-	L.SetField(result, "status_code", lua.LNumber(resp.StatusCode))
-	L.SetField(result, "headers", headers)
-	L.SetField(result, "content_length", lua.LNumber(resp.ContentLength))
-	L.Push(result)
+	L.Push(lua.LNil)
 	return 1
 }
 
+func (m *Module) downloadFile(opts *requestOptions, path string) error {
+	resp, cancel, err := m.do(opts)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("download %s failed, unexpected status code %d", opts.url, resp.StatusCode)
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	// Download into a temporary file, so that a broken download never replaces the path.
+	tmpPath := path + ".part"
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpPath)
+	defer f.Close()
+
+	progress := printer.NewMultiProgress()
+	_ = progress.Start()
+	bar := printer.NewDownloadBar(resp.ContentLength, filepath.Base(path), progress.NewWriter())
+	_, err = io.Copy(io.MultiWriter(f, bar), resp.Body)
+	_ = bar.Close()
+	progress.Stop()
+	if err != nil {
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
 func (m *Module) luaMap() map[string]lua.LGFunction {
 	return map[string]lua.LGFunction{
-		"get":  m.Get,
-		"head": m.Head,
+		"get":           m.Get,
+		"head":          m.Head,
+		"post":          m.Post,
+		"put":           m.Put,
+		"delete":        m.Delete,
+		"request":       m.Request,
+		"download_file": m.DownloadFile,
 	}
 }
 
 func NewModule(proxy *config.Proxy) lua.LGFunction {
 	return func(L *lua.LState) int {
-		client := &http.Client{}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.ResponseHeaderTimeout = headerTimeout
 		if proxy.Enable {
 			uri, err := url.Parse(proxy.Url)
 			if err == nil {
-				transPort := &http.Transport{
-					Proxy: http.ProxyURL(uri),
-				}
-				client = &http.Client{
-					Transport: transPort,
-				}
+				transport.Proxy = http.ProxyURL(uri)
 			}
 		}
+		client := &http.Client{Transport: transport}
 		m := &Module{proxy: proxy, client: client}
 		t := L.NewTable()
 		L.SetFuncs(t, m.luaMap())
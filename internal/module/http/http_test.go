@@ -17,9 +17,20 @@
 package http
 
 import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
 	"github.com/version-fox/vfox/internal/config"
+	"github.com/version-fox/vfox/internal/module/file"
 	lua "github.com/yuin/gopher-lua"
-	"testing"
 )
 
 func TestWithConfig(t *testing.T) {
@@ -73,6 +84,101 @@ func TestHeadRequest(t *testing.T) {
 	eval(str, t)
 }
 
+func newTestServer(t *testing.T) *httptest.Server {
+	var flaky atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/echo", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("X-Method", r.Method)
+		_, _ = fmt.Fprintf(w, "%s %s %s", r.Method, r.Header.Get("X-Token"), body)
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(time.Second)
+	})
+	mux.HandleFunc("/redirect", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/echo", http.StatusFound)
+	})
+	mux.HandleFunc("/flaky", func(w http.ResponseWriter, r *http.Request) {
+		if flaky.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	})
+	mux.HandleFunc("/file", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(strings.Repeat("vfox", 1024)))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRequestMethods(t *testing.T) {
+	server := newTestServer(t)
+	str := fmt.Sprintf(`
+	local http = require("http")
+	local url = %q
+	local resp, err = http.post({ url = url .. "/echo", headers = { ["X-Token"] = "token" }, body = "hello" })
+	assert(err == nil, err)
+	assert(resp.body == "POST token hello", resp.body)
+	resp, err = http.put({ url = url .. "/echo", body = "world" })
+	assert(resp.body == "PUT  world", resp.body)
+	resp, err = http.delete({ url = url .. "/echo" })
+	assert(resp.headers["X-Method"] == "DELETE")
+	resp, err = http.request({ method = "patch", url = url .. "/echo", body = "patch" })
+	assert(resp.body == "PATCH  patch", resp.body)
+	resp, err = http.request({ url = url .. "/echo" })
+	assert(resp.body == "GET  ", resp.body)
+	resp, err = http.head({ url = url .. "/echo" })
+	assert(resp.status_code == 200 and resp.body == nil)
+	resp, err = http.get({})
+	assert(resp == nil and err == "url is required")
+	`, server.URL)
+	eval(str, t)
+}
+
+func TestRequestOptions(t *testing.T) {
+	server := newTestServer(t)
+	str := fmt.Sprintf(`
+	local http = require("http")
+	local url = %q
+	local resp, err = http.get({ url = url .. "/slow", timeout = 0.1 })
+	assert(resp == nil and err ~= nil)
+	resp, err = http.get({ url = url .. "/redirect", follow_redirects = false })
+	assert(resp.status_code == 302, resp.status_code)
+	resp, err = http.get({ url = url .. "/redirect" })
+	assert(resp.status_code == 200, resp.status_code)
+	resp, err = http.get({ url = url .. "/flaky", retry = 1, retry_delay = 0 })
+	assert(resp.status_code == 503, resp.status_code)
+	resp, err = http.get({ url = url .. "/flaky", retry = 3, retry_delay = 0.01 })
+	assert(resp.status_code == 200 and resp.body == "ok", resp.status_code)
+	`, server.URL)
+	eval(str, t)
+}
+
+func TestDownloadFile(t *testing.T) {
+	server := newTestServer(t)
+	path := filepath.Join(t.TempDir(), "sub", "file.txt")
+	str := fmt.Sprintf(`
+	local http = require("http")
+	local err = http.download_file(%q, %q)
+	assert(err == nil, err)
+	err = http.download_file({ url = %q }, %q)
+	assert(err ~= nil)
+	`, server.URL+"/file", path, server.URL+"/missing", path+".missing")
+	eval(str, t)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != strings.Repeat("vfox", 1024) {
+		t.Errorf("unexpected content of the downloaded file, size %d", len(content))
+	}
+	if _, err = os.Stat(path + ".missing"); !os.IsNotExist(err) {
+		t.Errorf("expected no file for the failed download, got %v", err)
+	}
+}
+
 func eval(str string, t *testing.T) {
 	s := lua.NewState()
 	defer s.Close()
@@ -82,3 +188,29 @@ func eval(str string, t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestDownloadFileConfined(t *testing.T) {
+	server := newTestServer(t)
+	dir := t.TempDir()
+	root := filepath.Join(dir, "root")
+	s := lua.NewState()
+	defer s.Close()
+	s.PreloadModule("http", NewModule(config.EmptyProxy))
+	file.Bind(s, root)
+	str := fmt.Sprintf(`
+	local http = require("http")
+	local err = http.download_file(%q, "../file.txt")
+	assert(err ~= nil and string.find(err, "outside"), err)
+	err = http.download_file(%q, "sub/file.txt")
+	assert(err == nil, err)
+	`, server.URL+"/file", server.URL+"/file")
+	if err := s.DoString(str); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "file.txt")); !os.IsNotExist(err) {
+		t.Errorf("expected no file outside of the root, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "sub", "file.txt")); err != nil {
+		t.Errorf("expected the file to be downloaded into the root, err: %s", err)
+	}
+}
@@ -18,9 +18,14 @@ package module
 
 import (
 	"github.com/version-fox/vfox/internal/config"
+	"github.com/version-fox/vfox/internal/module/archiver"
+	"github.com/version-fox/vfox/internal/module/cmd"
+	"github.com/version-fox/vfox/internal/module/file"
 	"github.com/version-fox/vfox/internal/module/html"
 	"github.com/version-fox/vfox/internal/module/http"
 	"github.com/version-fox/vfox/internal/module/json"
+	"github.com/version-fox/vfox/internal/module/semver"
+	"github.com/version-fox/vfox/internal/module/strings"
 	lua "github.com/yuin/gopher-lua"
 )
 
@@ -28,4 +33,9 @@ func Preload(L *lua.LState, config *config.Config) {
 	L.PreloadModule("http", http.NewModule(config.Proxy))
 	json.Preload(L)
 	html.Preload(L)
+	semver.Preload(L)
+	strings.Preload(L)
+	file.Preload(L)
+	cmd.Preload(L)
+	archiver.Preload(L)
 }
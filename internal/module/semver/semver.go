@@ -0,0 +1,136 @@
+/*
+ *    Copyright 2024 Han Li and contributors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package semver
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/version-fox/vfox/internal/util"
+	lua "github.com/yuin/gopher-lua"
+)
+
+var versionRegex = regexp.MustCompile(`^[vV]?(\d+)(?:\.(\d+))?(?:\.(\d+))?(?:-([0-9A-Za-z.\-_]+))?(?:\+([0-9A-Za-z.\-_]+))?$`)
+
+// Parse splits a version into its parts, or returns nil and an error message if it is not a version.
+// The missing minor and patch numbers are 0.
+//
+//	local semver = require("semver")
+//	local v, err = semver.parse("v1.2.3-rc.1+build.5")
+//	-- v.major == 1, v.minor == 2, v.patch == 3, v.prerelease == "rc.1", v.build == "build.5"
+func Parse(L *lua.LState) int {
+	version := L.CheckString(1)
+	matches := versionRegex.FindStringSubmatch(version)
+	if matches == nil {
+		L.Push(lua.LNil)
+		L.Push(lua.LString("invalid version: " + version))
+		return 2
+	}
+	result := L.NewTable()
+	for i, key := range []string{"major", "minor", "patch"} {
+		n, _ := strconv.Atoi(matches[i+1])
+		result.RawSetString(key, lua.LNumber(n))
+	}
+	result.RawSetString("prerelease", lua.LString(matches[4]))
+	result.RawSetString("build", lua.LString(matches[5]))
+	L.Push(result)
+	return 1
+}
+
+// Compare returns 1 if v1 > v2, -1 if v1 < v2, otherwise 0.
+//
+//	semver.compare("1.10.0", "1.9.2") -- 1
+func Compare(L *lua.LState) int {
+	v1 := L.CheckString(1)
+	v2 := L.CheckString(2)
+	L.Push(lua.LNumber(util.CompareVersion(v1, v2)))
+	return 1
+}
+
+// Sort returns a new list of the versions sorted in ascending order, or descending order if the second
+// argument is true.
+//
+//	local versions = semver.sort({ "1.10.0", "1.9.2", "1.10.0-rc.1" }, true)
+//	-- { "1.10.0", "1.10.0-rc.1", "1.9.2" }
+func Sort(L *lua.LState) int {
+	table := L.CheckTable(1)
+	descending := L.OptBool(2, false)
+	versions := make([]string, 0, table.Len())
+	for i := 1; i <= table.Len(); i++ {
+		v, ok := table.RawGetInt(i).(lua.LString)
+		if !ok {
+			L.ArgError(1, "the versions must be strings")
+		}
+		versions = append(versions, string(v))
+	}
+	sort.SliceStable(versions, func(i, j int) bool {
+		if descending {
+			return util.CompareVersion(versions[i], versions[j]) > 0
+		}
+		return util.CompareVersion(versions[i], versions[j]) < 0
+	})
+	result := L.CreateTable(len(versions), 0)
+	for _, v := range versions {
+		result.Append(lua.LString(v))
+	}
+	L.Push(result)
+	return 1
+}
+
+// Satisfies reports whether the version matches the range, such as `^1.2`, `>=1.2 <2` or `18.x || 20.x`.
+// It returns false and an error message if the range is invalid.
+//
+//	local ok, err = semver.satisfies("20.11.0", "^20")
+func Satisfies(L *lua.LState) int {
+	version := L.CheckString(1)
+	constraint, err := util.NewConstraint(L.CheckString(2))
+	if err != nil {
+		L.Push(lua.LFalse)
+		L.Push(lua.LString(err.Error()))
+		return 2
+	}
+	L.Push(lua.LBool(constraint.Check(version)))
+	return 1
+}
+
+// IsPrerelease reports whether the version is an unstable version, such as `1.0.0-rc.1` or `21-ea`.
+// Vendor qualifiers like `17.0.2-tem` are not pre-releases.
+//
+//	semver.is_prerelease("1.0.0-beta.2") -- true
+func IsPrerelease(L *lua.LState) int {
+	L.Push(lua.LBool(util.IsPrerelease(L.CheckString(1))))
+	return 1
+}
+
+func loader(L *lua.LState) int {
+	t := L.NewTable()
+	L.SetFuncs(t, map[string]lua.LGFunction{
+		"parse":         Parse,
+		"compare":       Compare,
+		"sort":          Sort,
+		"satisfies":     Satisfies,
+		"is_prerelease": IsPrerelease,
+	})
+	L.Push(t)
+	return 1
+}
+
+// Preload registers the semver module.
+func Preload(L *lua.LState) {
+	L.PreloadModule("semver", loader)
+}
@@ -0,0 +1,66 @@
+/*
+ *    Copyright 2024 Han Li and contributors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package semver
+
+import (
+	"testing"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+func TestSemver(t *testing.T) {
+	str := `
+	local semver = require("semver")
+	local v, err = semver.parse("v1.2.3-rc.1+build.5")
+	assert(err == nil, err)
+	assert(v.major == 1 and v.minor == 2 and v.patch == 3, "parse numbers")
+	assert(v.prerelease == "rc.1" and v.build == "build.5", "parse suffixes")
+	v = semver.parse("21")
+	assert(v.major == 21 and v.minor == 0 and v.patch == 0 and v.prerelease == "", "parse partial")
+	v, err = semver.parse("latest")
+	assert(v == nil and err ~= nil, "parse invalid")
+
+	assert(semver.compare("1.10.0", "1.9.2") == 1)
+	assert(semver.compare("1.0.0-rc.1", "1.0.0") == -1)
+	assert(semver.compare("v2.0", "2.0.0") == 0)
+
+	local input = { "1.9.2", "1.10.0", "1.10.0-rc.1" }
+	local sorted = semver.sort(input)
+	assert(sorted[1] == "1.9.2" and sorted[2] == "1.10.0-rc.1" and sorted[3] == "1.10.0", "sort ascending")
+	assert(input[1] == "1.9.2" and input[2] == "1.10.0", "the input is not changed")
+	sorted = semver.sort(input, true)
+	assert(sorted[1] == "1.10.0" and sorted[3] == "1.9.2", "sort descending")
+	local ok = pcall(semver.sort, { "1.0.0", 2 })
+	assert(not ok, "sort non-string")
+
+	assert(semver.satisfies("20.11.0", "^20"))
+	assert(semver.satisfies("1.5.0", ">=1.2 <2"))
+	assert(not semver.satisfies("2.0.0", ">=1.2 <2"))
+	assert(not semver.satisfies("20.0.0-rc.1", "20"))
+	local ok, err = semver.satisfies("1.0.0", ">>1")
+	assert(ok == false and err ~= nil, "invalid range")
+
+	assert(semver.is_prerelease("1.0.0-beta.2"))
+	assert(not semver.is_prerelease("17.0.2-tem"))
+	`
+	s := lua.NewState()
+	defer s.Close()
+	Preload(s)
+	if err := s.DoString(str); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,161 @@
+/*
+ *    Copyright 2024 Han Li and contributors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package strings
+
+import (
+	"regexp"
+	"strings"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// Split splits the string around each instance of sep.
+//
+//	local strings = require("strings")
+//	local parts = strings.split("1.2.3", ".") -- { "1", "2", "3" }
+func Split(L *lua.LState) int {
+	s := L.CheckString(1)
+	sep := L.CheckString(2)
+	result := L.NewTable()
+	for _, part := range strings.Split(s, sep) {
+		result.Append(lua.LString(part))
+	}
+	L.Push(result)
+	return 1
+}
+
+// Trim removes the leading and trailing characters in cutset, or the white spaces if cutset is absent.
+//
+//	strings.trim("  v1.2.3\n")   -- "v1.2.3"
+//	strings.trim("v1.2.3", "v") -- "1.2.3"
+func Trim(L *lua.LState) int {
+	s := L.CheckString(1)
+	if L.GetTop() < 2 {
+		L.Push(lua.LString(strings.TrimSpace(s)))
+		return 1
+	}
+	L.Push(lua.LString(strings.Trim(s, L.CheckString(2))))
+	return 1
+}
+
+// HasPrefix reports whether the string begins with prefix.
+//
+//	strings.has_prefix("jdk-21", "jdk-") -- true
+func HasPrefix(L *lua.LState) int {
+	L.Push(lua.LBool(strings.HasPrefix(L.CheckString(1), L.CheckString(2))))
+	return 1
+}
+
+// HasSuffix reports whether the string ends with suffix.
+//
+//	strings.has_suffix("node.tar.gz", ".tar.gz") -- true
+func HasSuffix(L *lua.LState) int {
+	L.Push(lua.LBool(strings.HasSuffix(L.CheckString(1), L.CheckString(2))))
+	return 1
+}
+
+// Contains reports whether substr is within the string. Unlike string.find, substr is not a pattern.
+//
+//	strings.contains("linux-x64.tar.gz", "x64") -- true
+func Contains(L *lua.LState) int {
+	L.Push(lua.LBool(strings.Contains(L.CheckString(1), L.CheckString(2))))
+	return 1
+}
+
+// Join concatenates the elements of the list with sep between them.
+//
+//	strings.join({ "1", "2", "3" }, ".") -- "1.2.3"
+func Join(L *lua.LState) int {
+	table := L.CheckTable(1)
+	sep := L.OptString(2, "")
+	parts := make([]string, 0, table.Len())
+	for i := 1; i <= table.Len(); i++ {
+		value := table.RawGetInt(i)
+		switch value.Type() {
+		case lua.LTString, lua.LTNumber:
+			parts = append(parts, value.String())
+		default:
+			L.ArgError(1, "the elements must be strings or numbers")
+		}
+	}
+	L.Push(lua.LString(strings.Join(parts, sep)))
+	return 1
+}
+
+// Match returns the leftmost match of the regular expression followed by its submatches, nil if
+// there is no match, or nil and an error message if the expression is invalid.
+// The expression uses the Go syntax, see https://pkg.go.dev/regexp/syntax.
+//
+//	local m = strings.match("node-v20.11.0-linux-x64", [[v(\d+)\.(\d+)\.(\d+)]])
+//	-- { "v20.11.0", "20", "11", "0" }
+func Match(L *lua.LState) int {
+	s := L.CheckString(1)
+	re, err := regexp.Compile(L.CheckString(2))
+	if err != nil {
+		L.Push(lua.LNil)
+		L.Push(lua.LString(err.Error()))
+		return 2
+	}
+	matches := re.FindStringSubmatch(s)
+	if matches == nil {
+		L.Push(lua.LNil)
+		return 1
+	}
+	result := L.CreateTable(len(matches), 0)
+	for _, m := range matches {
+		result.Append(lua.LString(m))
+	}
+	L.Push(result)
+	return 1
+}
+
+// Replace replaces all matches of the regular expression with repl, in which `$1` or `${name}` stands
+// for the submatch. It returns nil and an error message if the expression is invalid.
+//
+//	strings.replace("jdk-21_35", [[_(\d+)$]], "+$1") -- "jdk-21+35"
+func Replace(L *lua.LState) int {
+	s := L.CheckString(1)
+	re, err := regexp.Compile(L.CheckString(2))
+	if err != nil {
+		L.Push(lua.LNil)
+		L.Push(lua.LString(err.Error()))
+		return 2
+	}
+	L.Push(lua.LString(re.ReplaceAllString(s, L.CheckString(3))))
+	return 1
+}
+
+func loader(L *lua.LState) int {
+	t := L.NewTable()
+	L.SetFuncs(t, map[string]lua.LGFunction{
+		"split":      Split,
+		"trim":       Trim,
+		"has_prefix": HasPrefix,
+		"has_suffix": HasSuffix,
+		"contains":   Contains,
+		"join":       Join,
+		"match":      Match,
+		"replace":    Replace,
+	})
+	L.Push(t)
+	return 1
+}
+
+// Preload registers the strings module.
+func Preload(L *lua.LState) {
+	L.PreloadModule("strings", loader)
+}
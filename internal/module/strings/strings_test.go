@@ -0,0 +1,61 @@
+/*
+ *    Copyright 2024 Han Li and contributors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package strings
+
+import (
+	"testing"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+func TestStrings(t *testing.T) {
+	str := `
+	local strings = require("strings")
+	local parts = strings.split("1.2.3", ".")
+	assert(#parts == 3 and parts[1] == "1" and parts[3] == "3", "split")
+	assert(strings.trim("  v1.2.3\n") == "v1.2.3", "trim spaces")
+	assert(strings.trim("v1.2.3v", "v") == "1.2.3", "trim cutset")
+	assert(strings.has_prefix("jdk-21", "jdk-"))
+	assert(not strings.has_prefix("jdk-21", "21"))
+	assert(strings.has_suffix("node.tar.gz", ".tar.gz"))
+	assert(strings.contains("linux-x64.tar.gz", "x64"))
+	assert(strings.contains("a.b", "."), "not a pattern")
+	assert(not strings.contains("ab", "."), "not a pattern")
+	assert(strings.join({ "1", 2, "3" }, ".") == "1.2.3", "join")
+	assert(strings.join({}) == "", "join empty")
+	local ok = pcall(strings.join, { "1", {} })
+	assert(not ok, "join table")
+
+	local m, err = strings.match("node-v20.11.0-linux-x64", [[v(\d+)\.(\d+)\.(\d+)]])
+	assert(err == nil, err)
+	assert(#m == 4 and m[1] == "v20.11.0" and m[2] == "20" and m[4] == "0", "match")
+	m, err = strings.match("latest", [[\d+]])
+	assert(m == nil and err == nil, "no match")
+	m, err = strings.match("latest", "(")
+	assert(m == nil and err ~= nil, "invalid match expression")
+
+	assert(strings.replace("jdk-21_35", [[_(\d+)$]], "+$1") == "jdk-21+35", "replace")
+	local s, err = strings.replace("a", "[", "")
+	assert(s == nil and err ~= nil, "invalid replace expression")
+	`
+	s := lua.NewState()
+	defer s.Close()
+	Preload(s)
+	if err := s.DoString(str); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,150 @@
+/*
+ *    Copyright 2024 Han Li and contributors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package internal
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/pterm/pterm"
+	"github.com/version-fox/vfox/internal/env"
+	"github.com/version-fox/vfox/internal/util"
+)
+
+// UpgradeLevel limits how far a version may be upgraded.
+type UpgradeLevel int
+
+const (
+	PatchUpgrade UpgradeLevel = iota
+	MinorUpgrade
+	MajorUpgrade
+)
+
+// Outdated is the newer versions available for the current version of a sdk.
+type Outdated struct {
+	Current Version
+	// Patch is the newest version with the same major and minor version
+	Patch Version
+	// Minor is the newest version with the same major version but another minor version
+	Minor Version
+	// Major is the newest version with another major version
+	Major Version
+}
+
+// IsOutdated reports whether there is any newer version.
+func (o *Outdated) IsOutdated() bool {
+	return o.Patch != "" || o.Minor != "" || o.Major != ""
+}
+
+// Target returns the newest version within the level, or empty if there is none.
+func (o *Outdated) Target(level UpgradeLevel) Version {
+	if level >= MajorUpgrade && o.Major != "" {
+		return o.Major
+	}
+	if level >= MinorUpgrade && o.Minor != "" {
+		return o.Minor
+	}
+	return o.Patch
+}
+
+// Outdated compares the available versions provided by the plugin with the current version,
+// or the highest installed version if the current one is not installed by vfox. Nil is returned if
+// no version is installed. Unstable versions are only considered if the current one is unstable too.
+func (b *Sdk) Outdated() (*Outdated, error) {
+	current := b.Current()
+	if _, custom := env.PathVersion(string(current)); current == "" || custom || !b.checkExists(current) {
+		current = ""
+		for _, v := range b.List() {
+			if current == "" || util.CompareVersion(string(v), string(current)) > 0 {
+				current = v
+			}
+		}
+	}
+	if current == "" {
+		return nil, nil
+	}
+	available, err := b.Available()
+	if err != nil {
+		return nil, err
+	}
+	result := &Outdated{Current: current}
+	prerelease := util.IsPrerelease(string(current))
+	newer := func(v, than Version) bool {
+		return than == "" || util.CompareVersion(string(v), string(than)) > 0
+	}
+	for _, pkg := range available {
+		v := pkg.Main.Version
+		if !newer(v, current) || (!prerelease && util.IsPrerelease(string(v))) {
+			continue
+		}
+		switch {
+		case util.HasSamePrefix(string(v), string(current), 2):
+			if newer(v, result.Patch) {
+				result.Patch = v
+			}
+		case util.HasSamePrefix(string(v), string(current), 1):
+			if newer(v, result.Minor) {
+				result.Minor = v
+			}
+		default:
+			if newer(v, result.Major) {
+				result.Major = v
+			}
+		}
+	}
+	return result, nil
+}
+
+// PinnedOrigin returns the file which pins the version of the sdk, see Record.Origin, and the scope
+// of the file. The file of the Project scope may be a .tool-versions in a parent directory or
+// a legacy version file. An empty path is returned if the version is not recorded.
+func (m *Manager) PinnedOrigin(name string) (string, UseScope) {
+	origin := m.Record.Origin(name)
+	if origin == "" {
+		return "", Global
+	}
+	switch filepath.Dir(origin) {
+	case filepath.Clean(m.PathMeta.ConfigPath):
+		return origin, Global
+	case filepath.Clean(m.PathMeta.CurTmpPath):
+		return origin, Session
+	default:
+		return origin, Project
+	}
+}
+
+// PinAt records the version in the .tool-versions at path, which may be in a parent directory of
+// the working directory. A legacy version file is never rewritten.
+func (b *Sdk) PinAt(version Version, path string) error {
+	if !env.IsRecordFile(path) {
+		return fmt.Errorf("%s is pinned in %s, which is not rewritten, please update it manually", b.Plugin.SdkName, path)
+	}
+	label := b.label(version)
+	if !b.checkExists(version) {
+		return fmt.Errorf("%s is not installed", label)
+	}
+	record, err := env.NewRecord(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	record.Add(b.Plugin.SdkName, string(version))
+	if err = record.Save(); err != nil {
+		return err
+	}
+	pterm.Printf("Now using %s in %s.\n", pterm.LightGreen(label), path)
+	return nil
+}
@@ -0,0 +1,87 @@
+/*
+ *    Copyright 2024 Han Li and contributors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOutdated(t *testing.T) {
+	manager, _ := newTestManager(t)
+	sdk, err := manager.LookupSdk("nodejs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	outdated, err := sdk.Outdated()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if outdated.Current != "20.0.0" || outdated.Patch != "20.0.5" || outdated.Minor != "20.3.0" || outdated.Major != "22.1.0" {
+		t.Errorf("unexpected outdated versions: %+v", outdated)
+	}
+	for level, want := range map[UpgradeLevel]Version{
+		PatchUpgrade: "20.0.5",
+		MinorUpgrade: "20.3.0",
+		MajorUpgrade: "22.1.0",
+	} {
+		if got := outdated.Target(level); got != want {
+			t.Errorf("unexpected target of level %d: %s, want %s", level, got, want)
+		}
+	}
+	patchOnly := &Outdated{Current: "20.0.0", Patch: "20.0.5"}
+	if got := patchOnly.Target(MajorUpgrade); got != "20.0.5" {
+		t.Errorf("unexpected target: %s", got)
+	}
+}
+
+func TestPinAt(t *testing.T) {
+	manager, _ := newTestManager(t)
+	installTestVersions(t, manager, "20.0.5")
+	sdk, err := manager.LookupSdk("nodejs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The old version is pinned in a parent directory
+	parent := t.TempDir()
+	path := filepath.Join(parent, ".tool-versions")
+	if err = os.WriteFile(path, []byte("# pinned\nnodejs 20.0.0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err = sdk.PinAt("20.0.5", path); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "# pinned\nnodejs 20.0.5\n" {
+		t.Errorf("unexpected record: %q", content)
+	}
+	if err = sdk.PinAt("20.0.5", filepath.Join(parent, ".nvmrc")); err == nil {
+		t.Error("expected an error for a legacy version file")
+	}
+	if err = sdk.PinAt("20.3.0", path); err == nil {
+		t.Error("expected an error for a version not installed")
+	}
+
+	// 20.0.0 is the current version
+	if !sdk.IsReferenced("20.0.0") || sdk.IsReferenced("20.0.5") {
+		t.Error("unexpected referenced versions")
+	}
+}
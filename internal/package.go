@@ -18,19 +18,26 @@ package internal
 
 import (
 	"path/filepath"
+
+	"github.com/version-fox/vfox/internal/util"
 )
 
 type Package struct {
 	Main      *Info
 	Additions []*Info
+	// Aliases are provided by the Available hook, such as lts or stable
+	Aliases []string
 }
 
 type Info struct {
-	Name     string  `luai:"name"`
-	Version  Version `luai:"version"`
-	Path     string  `luai:"path"`
-	Note     string  `luai:"note"`
-	Checksum *Checksum
+	Name      string  `luai:"name"`
+	Version   Version `luai:"version"`
+	Path      string  `luai:"path"`
+	Note      string  `luai:"note"`
+	Checksum  *Checksum
+	Signature *Signature
+	// Decompress customizes how the archive is unpacked, nil for the defaults.
+	Decompress *util.DecompressOptions
 }
 
 func (i *Info) label() string {
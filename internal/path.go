@@ -40,6 +40,8 @@ type PathMeta struct {
 	ConfigPath       string
 	SdkCachePath     string
 	PluginPath       string
+	KeysPath         string
+	ShimsPath        string
 	ExecutablePath   string
 	WorkingDirectory string
 }
@@ -53,6 +55,8 @@ func newPathMeta() (*PathMeta, error) {
 	configPath := filepath.Join(userHomeDir, ".version-fox")
 	sdkCachePath := filepath.Join(userHomeDir, ".version-fox", "cache")
 	tmpPath := filepath.Join(userHomeDir, ".version-fox", "temp")
+	keysPath := filepath.Join(userHomeDir, ".version-fox", "keys")
+	shimsPath := filepath.Join(userHomeDir, ".version-fox", "shims")
 	_ = os.MkdirAll(sdkCachePath, 0755)
 	_ = os.MkdirAll(pluginPath, 0755)
 	_ = os.MkdirAll(tmpPath, 0755)
@@ -82,6 +86,8 @@ func newPathMeta() (*PathMeta, error) {
 		ConfigPath:       configPath,
 		SdkCachePath:     sdkCachePath,
 		PluginPath:       pluginPath,
+		KeysPath:         keysPath,
+		ShimsPath:        shimsPath,
 		ExecutablePath:   exePath,
 		WorkingDirectory: workingDirectory,
 	}, nil
@@ -17,16 +17,22 @@
 package internal
 
 import (
+	"context"
 	_ "embed"
 	"errors"
 	"fmt"
 	"github.com/version-fox/vfox/internal/env"
 	"github.com/version-fox/vfox/internal/logger"
 	"github.com/version-fox/vfox/internal/luai"
+	"github.com/version-fox/vfox/internal/module/cmd"
+	"github.com/version-fox/vfox/internal/module/file"
 	"github.com/version-fox/vfox/internal/util"
 	lua "github.com/yuin/gopher-lua"
+	"io"
+	"os"
 	"path/filepath"
 	"regexp"
+	"strings"
 )
 
 const (
@@ -68,10 +74,14 @@ func (l *LuaPlugin) Close() {
 	l.vm.Close()
 }
 
-func (l *LuaPlugin) Available() ([]*Package, error) {
+func (l *LuaPlugin) Available(args ...string) ([]*Package, error) {
 	L := l.vm.Instance
+	if args == nil {
+		args = []string{}
+	}
 	ctxTable, err := luai.Marshal(L, AvailableHookCtx{
 		RuntimeVersion: RuntimeVersion,
+		Args:           args,
 	})
 
 	if err != nil {
@@ -121,6 +131,7 @@ func (l *LuaPlugin) Available() ([]*Package, error) {
 		result = append(result, &Package{
 			Main:      mainSdk,
 			Additions: additionalArr,
+			Aliases:   item.Aliases,
 		})
 	}
 
@@ -176,6 +187,19 @@ func (l *LuaPlugin) PreInstall(version Version) (*Package, error) {
 	}, nil
 }
 
+// bindInstall binds the lua state to an installation until the returned func is called:
+// the hooks are interrupted once ctx is canceled, and the commands run through the cmd module
+// are recorded into the log.
+func (l *LuaPlugin) bindInstall(ctx context.Context, log io.Writer) func() {
+	L := l.vm.Instance
+	L.SetContext(ctx)
+	cmd.Bind(L, log)
+	return func() {
+		cmd.Bind(L, nil)
+		L.RemoveContext()
+	}
+}
+
 func (l *LuaPlugin) PostInstall(rootPath string, sdks []*Info) error {
 	L := l.vm.Instance
 
@@ -198,6 +222,9 @@ func (l *LuaPlugin) PostInstall(rootPath string, sdks []*Info) error {
 		return err
 	}
 
+	// The file module is confined to the install path of the version.
+	file.Bind(L, rootPath)
+	defer file.Bind(L, "")
 	if err = l.CallFunction("PostInstall", ctxTable); err != nil {
 		return err
 	}
@@ -314,6 +341,63 @@ func (l *LuaPlugin) PreUse(version Version, previousVersion Version, scope UseSc
 	return Version(result.Version), nil
 }
 
+// ParseLegacyFile reads the version from a legacy version file, such as .nvmrc.
+// If the plugin does not implement the ParseLegacyFile hook, the first line of the file is used.
+func (l *LuaPlugin) ParseLegacyFile(path string) (Version, error) {
+	if !l.HasFunction("ParseLegacyFile") {
+		return parseLegacyFile(path)
+	}
+	L := l.vm.Instance
+
+	ctx := ParseLegacyFileHookCtx{
+		RuntimeVersion: RuntimeVersion,
+		Filepath:       path,
+		Filename:       filepath.Base(path),
+	}
+
+	ctxTable, err := luai.Marshal(L, ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if err = l.CallFunction("ParseLegacyFile", ctxTable); err != nil {
+		return "", err
+	}
+
+	table := l.vm.ReturnedValue()
+	if table == nil || table.Type() == lua.LTNil {
+		return "", nil
+	}
+
+	result := &ParseLegacyFileHookResult{}
+
+	if err := luai.Unmarshal(table, result); err != nil {
+		return "", err
+	}
+
+	return Version(result.Version), nil
+}
+
+// parseLegacyFile returns the first line of the file which is not empty or a comment,
+// a leading v of the version is removed, such as v20.1.0 in .nvmrc.
+func parseLegacyFile(path string) (Version, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if len(line) > 1 && (line[0] == 'v' || line[0] == 'V') && line[1] >= '0' && line[1] <= '9' {
+			line = line[1:]
+		}
+		return Version(line), nil
+	}
+	return "", nil
+}
+
 func (l *LuaPlugin) CallFunction(funcName string, args ...lua.LValue) error {
 	logger.Debugf("CallFunction: %s\n", funcName)
 	if err := l.vm.CallFunction(l.pluginObj.RawGetString(funcName), append([]lua.LValue{l.pluginObj}, args...)...); err != nil {
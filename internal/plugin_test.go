@@ -86,6 +86,45 @@ func TestPlugin(t *testing.T) {
 		}
 	})
 
+	t.Run("AvailableArgs", func(t *testing.T) {
+		manager := NewSdkManager()
+		content := strings.Replace(pluginContent, `note = "LTS",`, `note = table.concat(ctx.args, " "),`, 1)
+		plugin, err := NewLuaPlugin(content, pluginPath, manager)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		pkgs, err := plugin.Available("temurin", "17")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(pkgs) != 1 || pkgs[0].Main.Note != "temurin 17" {
+			t.Errorf("expected the args to be passed to the Available hook, got %+v", pkgs[0].Main)
+		}
+	})
+
+	t.Run("PreInstallDecompress", func(t *testing.T) {
+		manager := NewSdkManager()
+		content := strings.Replace(pluginContent, `version = "version",`, `version = "version", decompress = { format = "tar.xz", strip = 0 },`, 1)
+		plugin, err := NewLuaPlugin(content, pluginPath, manager)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		pkg, err := plugin.PreInstall("9.0.0")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if opts := pkg.Main.Decompress; opts == nil || opts.Format != "tar.xz" || opts.Strip != 0 {
+			t.Errorf("expected the decompress options of the main file, got %+v", opts)
+		}
+		if pkg.Additions[0].Decompress != nil {
+			t.Errorf("expected no decompress options of the addition, got %+v", pkg.Additions[0].Decompress)
+		}
+	})
+
 	t.Run("PreInstall", func(t *testing.T) {
 		manager := NewSdkManager()
 		plugin, err := NewLuaPlugin(pluginContent, pluginPath, manager)
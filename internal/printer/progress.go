@@ -0,0 +1,145 @@
+/*
+ *    Copyright 2024 Han Li and contributors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package printer
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/schollz/progressbar/v3"
+)
+
+// MultiProgress renders several progress bars at the same time, one line per bar.
+// Each bar writes into its own line through the writer returned by NewWriter,
+// the writers are safe to be used from different goroutines.
+type MultiProgress struct {
+	mu    sync.Mutex
+	lines []*progressLine
+	area  *pterm.AreaPrinter
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+type progressLine struct {
+	mu      *sync.Mutex
+	content string
+}
+
+// Write keeps only the last rendered state of the bar, the bars usually redraw themselves with `\r`.
+func (l *progressLine) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	parts := strings.Split(string(p), "\r")
+	for i := len(parts) - 1; i >= 0; i-- {
+		if s := strings.Trim(parts[i], "\r\n"); s != "" {
+			l.content = s
+			break
+		}
+	}
+	return len(p), nil
+}
+
+// NewWriter adds a new line to the view and returns its writer.
+func (m *MultiProgress) NewWriter() io.Writer {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	line := &progressLine{mu: &m.mu}
+	m.lines = append(m.lines, line)
+	return line
+}
+
+func (m *MultiProgress) render() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var content []string
+	for _, line := range m.lines {
+		content = append(content, line.content)
+	}
+	return strings.Join(content, "\n")
+}
+
+// Start starts rendering the bars periodically until Stop is called.
+// Nothing is rendered if Interactive is false.
+func (m *MultiProgress) Start() error {
+	if !Interactive {
+		return nil
+	}
+	area, err := pterm.DefaultArea.Start()
+	if err != nil {
+		return fmt.Errorf("could not start area: %w", err)
+	}
+	m.area = area
+	m.stop = make(chan struct{})
+	m.done = make(chan struct{})
+	go func() {
+		defer close(m.done)
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-m.stop:
+				return
+			case <-ticker.C:
+				m.area.Update(m.render())
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop renders the final state of the bars and stops rendering.
+func (m *MultiProgress) Stop() {
+	if m.area == nil {
+		return
+	}
+	close(m.stop)
+	<-m.done
+	m.area.Update(m.render())
+	_ = m.area.Stop()
+	m.area = nil
+	pterm.Println()
+}
+
+func NewMultiProgress() *MultiProgress {
+	return &MultiProgress{}
+}
+
+// NewDownloadBar returns the progress bar of a download, the total is the size in bytes, or -1 if unknown.
+func NewDownloadBar(total int64, description string, writer io.Writer) *progressbar.ProgressBar {
+	return progressbar.NewOptions64(
+		total,
+		progressbar.OptionSetWriter(writer),
+		progressbar.OptionEnableColorCodes(true),
+		progressbar.OptionShowBytes(true),
+		progressbar.OptionFullWidth(),
+		progressbar.OptionOnCompletion(func() {
+			fmt.Fprintf(writer, "\n")
+		}),
+		progressbar.OptionSetDescription(description),
+		progressbar.OptionSetTheme(progressbar.Theme{
+			Saucer:        "[green]=[reset]",
+			SaucerHead:    "[green]>[reset]",
+			SaucerPadding: " ",
+			BarStart:      "[",
+			BarEnd:        "]",
+		}),
+	)
+}
@@ -28,6 +28,10 @@ import (
 	"strings"
 )
 
+// Interactive is false when the output is machine-readable, such as json,
+// the progress is not rendered and no prompt should be shown.
+var Interactive = true
+
 type PageKVSelect struct {
 	index             int
 	Options           []*KV
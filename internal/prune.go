@@ -0,0 +1,204 @@
+/*
+ *    Copyright 2024 Han Li and contributors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package internal
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/version-fox/vfox/internal/env"
+	"github.com/version-fox/vfox/internal/logger"
+	"github.com/version-fox/vfox/internal/util"
+)
+
+// PruneOptions limits the versions to be pruned.
+type PruneOptions struct {
+	// Sdks are the sdks to be pruned, all sdks if empty
+	Sdks []string
+	// KeepLatest keeps the highest versions of each sdk
+	KeepLatest int
+	// ProjectRoots are searched for .tool-versions in addition to Config.Project.Roots
+	ProjectRoots []string
+}
+
+// PruneCandidate is an installed version which is not referenced by any record.
+type PruneCandidate struct {
+	Sdk     *Sdk
+	Version Version
+	Size    int64
+}
+
+// PruneCandidates returns the installed versions which are not referenced by the global record,
+// the live session records, the current project and the .tool-versions under the project roots.
+func (m *Manager) PruneCandidates(opts *PruneOptions) ([]*PruneCandidate, error) {
+	names := opts.Sdks
+	all := len(names) == 0
+	if all {
+		dir, err := os.ReadDir(m.PathMeta.PluginPath)
+		if err != nil {
+			return nil, fmt.Errorf("load sdks error: %w", err)
+		}
+		for _, d := range dir {
+			if d.IsDir() {
+				names = append(names, d.Name())
+			}
+		}
+	}
+	sort.Strings(names)
+	referenced := m.referencedVersions(append(append([]string{}, m.Config.Project.Roots...), opts.ProjectRoots...))
+
+	var candidates []*PruneCandidate
+	for _, name := range names {
+		sdk, err := m.LookupSdk(name)
+		if err != nil {
+			if !all {
+				return nil, fmt.Errorf("%s not supported, error: %w", name, err)
+			}
+			// A broken plugin must not stop pruning the others.
+			pterm.Printf("%s: Skip %s plugin, err: %s\n", pterm.LightYellow("WARNING"), name, err)
+			continue
+		}
+		installed := sdk.List()
+		sort.Slice(installed, func(i, j int) bool {
+			return util.CompareVersion(string(installed[i]), string(installed[j])) > 0
+		})
+		keep := make(map[Version]struct{})
+		for i, version := range installed {
+			if i < opts.KeepLatest {
+				keep[version] = struct{}{}
+			}
+		}
+		for _, version := range referenced[strings.ToLower(sdk.Plugin.SdkName)] {
+			if !sdk.checkExists(version) {
+				version = sdk.resolveLocalVersion(version)
+			}
+			keep[version] = struct{}{}
+		}
+		for _, version := range installed {
+			// The linked versions are not managed by vfox.
+			if _, ok := keep[version]; ok || sdk.IsLinked(version) {
+				continue
+			}
+			candidates = append(candidates, &PruneCandidate{
+				Sdk:     sdk,
+				Version: version,
+				Size:    util.DirSize(sdk.VersionPath(version)),
+			})
+		}
+	}
+	return candidates, nil
+}
+
+// Prune uninstalls the versions and returns the disk space reclaimed.
+func (m *Manager) Prune(candidates []*PruneCandidate) (int64, error) {
+	var reclaimed int64
+	for _, candidate := range candidates {
+		if err := candidate.Sdk.Uninstall(candidate.Version); err != nil {
+			return reclaimed, err
+		}
+		reclaimed += candidate.Size
+	}
+	return reclaimed, nil
+}
+
+// IsReferenced reports whether the version is referenced by any record which PruneCandidates respects,
+// in which case the version should not be uninstalled.
+func (b *Sdk) IsReferenced(version Version) bool {
+	m := b.sdkManager
+	for _, v := range m.referencedVersions(m.Config.Project.Roots)[strings.ToLower(b.Plugin.SdkName)] {
+		if !b.checkExists(v) {
+			v = b.resolveLocalVersion(v)
+		}
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// referencedVersions collects the versions of each sdk recorded in the records of the manager, the global record,
+// the session records which have not expired and the .tool-versions found under the roots.
+func (m *Manager) referencedVersions(roots []string) map[string][]Version {
+	records := []env.Record{m.Record}
+	dirs := []string{m.PathMeta.ConfigPath}
+	// The session records expire at the end of the day, see CleanTmp.
+	if entries, err := os.ReadDir(m.PathMeta.TempPath); err == nil {
+		for _, entry := range entries {
+			timestamp, _, ok := strings.Cut(entry.Name(), "-")
+			if !entry.IsDir() || !ok {
+				continue
+			}
+			if i, err := strconv.ParseInt(timestamp, 10, 64); err == nil && !util.IsBeforeToday(i) {
+				dirs = append(dirs, filepath.Join(m.PathMeta.TempPath, entry.Name()))
+			}
+		}
+	}
+	for _, root := range roots {
+		if rest, ok := strings.CutPrefix(root, "~"); ok {
+			if home, err := os.UserHomeDir(); err == nil {
+				root = home + rest
+			}
+		}
+		dirs = append(dirs, findRecordDirs(root)...)
+	}
+	for _, dir := range dirs {
+		if !env.IsRecordExist(dir) {
+			continue
+		}
+		record, err := env.NewRecord(dir)
+		if err != nil {
+			logger.Debugf("Failed to read the record in %s, err: %s\n", dir, err)
+			continue
+		}
+		records = append(records, record)
+	}
+
+	result := make(map[string][]Version)
+	for _, record := range records {
+		for name := range record.Export() {
+			for _, version := range record.Versions(name) {
+				result[strings.ToLower(name)] = append(result[strings.ToLower(name)], Version(version))
+			}
+		}
+	}
+	return result
+}
+
+// findRecordDirs returns the directories containing .tool-versions under the root,
+// the hidden directories and node_modules are skipped.
+func findRecordDirs(root string) []string {
+	var dirs []string
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		if path != root && (strings.HasPrefix(d.Name(), ".") || d.Name() == "node_modules") {
+			return filepath.SkipDir
+		}
+		if env.IsRecordExist(path) {
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+	return dirs
+}
@@ -0,0 +1,95 @@
+/*
+ *    Copyright 2024 Han Li and contributors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/version-fox/vfox/internal/util"
+)
+
+func TestPruneCandidates(t *testing.T) {
+	manager, _ := newTestManager(t)
+	dir := t.TempDir()
+	live := filepath.Join(manager.PathMeta.TempPath, fmt.Sprintf("%d-1", util.GetBeginOfToday()))
+	expired := filepath.Join(manager.PathMeta.TempPath, "1000-2")
+	root := filepath.Join(dir, "projects")
+	for _, path := range []string{live, expired, filepath.Join(root, "app"), filepath.Join(root, ".cache")} {
+		if err := os.MkdirAll(path, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files := map[string]string{
+		filepath.Join(manager.PathMeta.ConfigPath, ".tool-versions"): "nodejs 21.0.0\n",
+		filepath.Join(live, ".tool-versions"):                        "nodejs 19.0.0\n",
+		filepath.Join(expired, ".tool-versions"):                     "nodejs 18.0.0\n",
+		filepath.Join(root, "app", ".tool-versions"):                 "nodejs 17 16.0.0\n",
+		filepath.Join(root, ".cache", ".tool-versions"):              "nodejs 15.0.0\n",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	installTestVersions(t, manager, "22.0.0", "21.0.0", "19.0.0", "18.0.0", "17.1.0", "16.0.0", "15.0.0")
+
+	versions := func(opts *PruneOptions) []Version {
+		candidates, err := manager.PruneCandidates(opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var result []Version
+		for _, candidate := range candidates {
+			result = append(result, candidate.Version)
+		}
+		return result
+	}
+	// 20.0.0 is the current version, 21.0.0 is global and 19.0.0 is in a live session
+	got := fmt.Sprint(versions(&PruneOptions{}))
+	if want := "[22.0.0 18.0.0 17.1.0 16.0.0 15.0.0]"; got != want {
+		t.Errorf("unexpected candidates: %s, want %s", got, want)
+	}
+	// 17 is resolved to 17.1.0, the hidden directories are skipped
+	got = fmt.Sprint(versions(&PruneOptions{ProjectRoots: []string{root}, KeepLatest: 1}))
+	if want := "[18.0.0 15.0.0]"; got != want {
+		t.Errorf("unexpected candidates: %s, want %s", got, want)
+	}
+	if _, err := manager.PruneCandidates(&PruneOptions{Sdks: []string{"python"}}); err == nil {
+		t.Error("expected an error for an unknown sdk")
+	}
+
+	// A broken plugin is skipped
+	if err := os.MkdirAll(filepath.Join(manager.PathMeta.PluginPath, "broken"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(manager.PathMeta.PluginPath, "broken", "main.lua"), []byte("PLUGIN = "), 0644); err != nil {
+		t.Fatal(err)
+	}
+	candidates, err := manager.PruneCandidates(&PruneOptions{ProjectRoots: []string{root}, KeepLatest: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = manager.Prune(candidates); err != nil {
+		t.Fatal(err)
+	}
+	if got = fmt.Sprint(versions(&PruneOptions{})); got != "[22.0.0 17.1.0 16.0.0]" {
+		t.Errorf("unexpected candidates after pruning: %s", got)
+	}
+}
@@ -24,106 +24,180 @@ import (
 	"net/http"
 	"net/url"
 	"os"
-	"os/signal"
 	"path/filepath"
 	"sort"
 	"strings"
-	"syscall"
 
-	"github.com/schollz/progressbar/v3"
 	"github.com/version-fox/vfox/internal/env"
 	"github.com/version-fox/vfox/internal/logger"
 	"github.com/version-fox/vfox/internal/shell"
 
 	"github.com/pterm/pterm"
+	"github.com/version-fox/vfox/internal/printer"
 	"github.com/version-fox/vfox/internal/util"
 )
 
 type Version string
 
+// ErrAlreadyInstalled is returned by Install if the version is already installed.
+var ErrAlreadyInstalled = errors.New("already installed")
+
 type Sdk struct {
 	sdkManager *Manager
 	Plugin     *LuaPlugin
 	// current sdk install path
 	InstallPath string
+	// the user-defined version aliases, loaded lazily
+	aliases *Aliases
+	// whether the unsupported ref: versions have been reported
+	refReported bool
 }
 
 func (b *Sdk) Install(version Version) error {
+	plan, err := b.prepareInstall(version)
+	if err != nil {
+		return err
+	}
+	return b.sdkManager.install([]*installPlan{plan})[0]
+}
+
+// prepareInstall calls the PreInstall hook of the plugin and collects the files to be downloaded.
+func (b *Sdk) prepareInstall(version Version) (*installPlan, error) {
+	if strings.HasPrefix(string(version), env.RefVersionPrefix) {
+		return nil, fmt.Errorf("%s is not supported, a version built from a git ref can not be installed", b.label(version))
+	}
+	if dir, ok := env.PathVersion(string(version)); ok {
+		if b.checkExists(version) {
+			return nil, fmt.Errorf("%s is %w", b.label(version), ErrAlreadyInstalled)
+		}
+		return nil, fmt.Errorf("%s is not found, a version in a custom directory is not installed by vfox", dir)
+	}
+	version = b.resolveRemoteVersion(version)
 	label := b.label(version)
 	if b.checkExists(version) {
-		return fmt.Errorf("%s is already installed", label)
+		return nil, fmt.Errorf("%s is %w", label, ErrAlreadyInstalled)
 	}
 	installInfo, err := b.Plugin.PreInstall(version)
 	if err != nil {
-		return fmt.Errorf("plugin [PreInstall] method error: %w", err)
+		return nil, fmt.Errorf("plugin [PreInstall] method error: %w", err)
 	}
 	if installInfo == nil {
-		return fmt.Errorf("no information about the current version")
+		return nil, fmt.Errorf("no information about the current version")
 	}
 	mainSdk := installInfo.Main
-	success := false
-	newDirPath := b.VersionPath(mainSdk.Version)
-
-	sigs := make(chan os.Signal, 1)
+	// A second check is required because the plug-in may change the version number,
+	// for example, latest is resolved to a specific version number.
+	label = b.label(mainSdk.Version)
+	if b.checkExists(mainSdk.Version) {
+		return nil, fmt.Errorf("%s is %w", label, ErrAlreadyInstalled)
+	}
+	return b.newInstallPlan(installInfo)
+}
 
-	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+// prepareFrozenInstall collects the files to be downloaded exactly as locked in the project lock,
+// the PreInstall hook of the plugin is not called.
+func (b *Sdk) prepareFrozenInstall(version Version, lock *ProjectLock) (*installPlan, error) {
+	name := strings.ToLower(b.Plugin.SdkName)
+	resolved, locked, err := lock.Lookup(name, b.resolveAlias(version), b.sdkManager.platform())
+	if err != nil {
+		return nil, err
+	}
+	if b.checkExists(resolved) {
+		return nil, fmt.Errorf("%s is %w", b.label(resolved), ErrAlreadyInstalled)
+	}
+	pkg := locked.Package()
+	for _, info := range append([]*Info{pkg.Main}, pkg.Additions...) {
+		if isRemotePath(info.Path) && info.Checksum == NoneChecksum {
+			return nil, fmt.Errorf("no checksum of %s is locked in %s", info.Path, lock.path)
+		}
+	}
+	plan, err := b.newInstallPlan(pkg)
+	if err != nil {
+		return nil, err
+	}
+	plan.frozen = true
+	return plan, nil
+}
 
-	go func() {
-		_ = <-sigs
-		if !success {
-			_ = os.RemoveAll(newDirPath)
+func (b *Sdk) newInstallPlan(pkg *Package) (*installPlan, error) {
+	label := b.label(pkg.Main.Version)
+	plan := &installPlan{
+		sdk:      b,
+		label:    label,
+		rootPath: b.VersionPath(pkg.Main.Version),
+		pkg:      pkg,
+	}
+	for i, info := range plan.infos() {
+		if !isRemotePath(info.Path) {
+			continue
+		}
+		u, err := url.Parse(info.Path)
+		if err != nil {
+			return nil, err
 		}
-		os.Exit(0)
-	}()
+		taskLabel := label
+		if i > 0 {
+			taskLabel = fmt.Sprintf("%s (%s)", label, info.Name)
+		}
+		plan.downloads = append(plan.downloads, &downloadTask{
+			sdk:   b,
+			info:  info,
+			url:   u,
+			label: taskLabel,
+		})
+	}
+	return plan, nil
+}
 
-	// Delete directory after failed installation
-	defer func() {
-		if !success {
-			_ = os.RemoveAll(newDirPath)
+// verifySignature verifies the signature of the downloaded file with the keys
+// under the keys directory, according to the signature policy.
+func (b *Sdk) verifySignature(info *Info, filePath string) error {
+	name := b.Plugin.SdkName
+	required := b.sdkManager.Config.Signature.IsRequired(name)
+	if info.Signature == nil {
+		if required {
+			return fmt.Errorf("a signature is required for %s, but the plugin does not provide one", name)
 		}
-	}()
-	// A second check is required because the plug-in may change the version number,
-	// for example, latest is resolved to a specific version number.
-	label = b.label(mainSdk.Version)
-	if b.checkExists(mainSdk.Version) {
-		return fmt.Errorf("%s is already installed", label)
+		return nil
 	}
-	var installedSdkInfos []*Info
-	path, err := b.preInstallSdk(mainSdk, newDirPath)
+	keys, err := info.Signature.publicKeys(b.sdkManager.PathMeta.KeysPath, name)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to load public keys, err:%w", err)
 	}
-	installedSdkInfos = append(installedSdkInfos, &Info{
-		Name:    mainSdk.Name,
-		Version: mainSdk.Version,
-		Note:    mainSdk.Note,
-		Path:    path,
-	})
-	if len(installInfo.Additions) > 0 {
-		pterm.Printf("There are %d additional files that need to be downloaded...\n", len(installInfo.Additions))
-		for _, oSdk := range installInfo.Additions {
-			path, err = b.preInstallSdk(oSdk, newDirPath)
-			if err != nil {
-				return err
-			}
-			installedSdkInfos = append(installedSdkInfos, &Info{
-				Name:    oSdk.Name,
-				Version: oSdk.Version,
-				Path:    path,
-			})
+	if len(keys) == 0 {
+		if required {
+			return fmt.Errorf("a signature is required for %s, but no public key is found in %s", name, b.sdkManager.PathMeta.KeysPath)
 		}
+		pterm.Printf("Warning: No public key found for %s, skip verifying the signature.\n", name)
+		return nil
 	}
-	err = b.Plugin.PostInstall(newDirPath, installedSdkInfos)
+	signature, err := b.readSignature(info.Signature.Url)
 	if err != nil {
-		return fmt.Errorf("plugin [PostInstall] method error: %w", err)
+		return fmt.Errorf("failed to fetch the signature, err:%w", err)
 	}
-	success = true
-	pterm.Printf("Install %s success! \n", pterm.LightGreen(label))
-	pterm.Printf("Please use %s to use it.\n", pterm.LightBlue(fmt.Sprintf("vfox use %s", label)))
-	return nil
+	pterm.Printf("Verifying signature %s...\n", info.Signature.Url)
+	return info.Signature.verify(filePath, signature, keys)
+}
+
+func (b *Sdk) readSignature(path string) ([]byte, error) {
+	if !isRemotePath(path) {
+		return os.ReadFile(path)
+	}
+	u, err := url.Parse(path)
+	if err != nil {
+		return nil, err
+	}
+	entry, err := b.download(u, NoneChecksum, "", io.Discard)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(entry.FilePath())
 }
 
 func (b *Sdk) moveLocalFile(info *Info, targetPath string) error {
+	if err := b.verifySignature(info, info.Path); err != nil {
+		return err
+	}
 	pterm.Printf("Moving %s to %s...\n", info.Path, targetPath)
 	if err := util.MoveFiles(info.Path, targetPath); err != nil {
 		return fmt.Errorf("failed to move file, err:%w", err)
@@ -131,32 +205,20 @@ func (b *Sdk) moveLocalFile(info *Info, targetPath string) error {
 	return nil
 }
 
-func (b *Sdk) moveRemoteFile(info *Info, targetPath string) error {
-	u, err := url.Parse(info.Path)
-	label := info.label()
-	if err != nil {
+func (b *Sdk) moveRemoteFile(info *Info, filePath, targetPath string) error {
+	if err := b.verifySignature(info, filePath); err != nil {
 		return err
 	}
-	filePath, err := b.Download(u)
+	decompressor, err := util.NewDecompressorWithOptions(filePath, info.Decompress)
 	if err != nil {
-		return fmt.Errorf("failed to download %s file, err:%w", label, err)
-	}
-	defer func() {
-		// del cache file
-		_ = os.Remove(filePath)
-	}()
-	pterm.Printf("Verifying checksum %s...\n", info.Checksum.Value)
-	checksum := info.Checksum.verify(filePath)
-	if !checksum {
-		fmt.Printf("Checksum error, file: %s\n", filePath)
-		return errors.New("checksum error")
-	}
-	decompressor := util.NewDecompressor(filePath)
+		return err
+	}
 	if decompressor == nil {
-		// If it is not a compressed file, move file to the corresponding sdk directory,
+		// If it is not a compressed file, copy file to the corresponding sdk directory,
 		// and the rest be handled by the PostInstall function.
-		if err = util.MoveFiles(filePath, targetPath); err != nil {
-			return fmt.Errorf("failed to move file, err:%w", err)
+		// The file is copied rather than moved to keep it in the download cache.
+		if err := util.CopyFile(filePath, filepath.Join(targetPath, filepath.Base(filePath))); err != nil {
+			return fmt.Errorf("failed to copy file, err:%w", err)
 		}
 		return nil
 	}
@@ -167,7 +229,10 @@ func (b *Sdk) moveRemoteFile(info *Info, targetPath string) error {
 	}
 	return nil
 }
-func (b *Sdk) preInstallSdk(info *Info, sdkDestPath string) (string, error) {
+
+// preInstallSdk moves the file of info into the sdk directory,
+// the remote file must have been downloaded by task already.
+func (b *Sdk) preInstallSdk(info *Info, sdkDestPath string, task *downloadTask) (string, error) {
 	pterm.Printf("Preinstalling %s...\n", info.label())
 	path := info.storagePath(sdkDestPath)
 	if !util.FileExists(path) {
@@ -178,8 +243,13 @@ func (b *Sdk) preInstallSdk(info *Info, sdkDestPath string) (string, error) {
 	if info.Path == "" {
 		return path, nil
 	}
-	if strings.HasPrefix(info.Path, "https://") || strings.HasPrefix(info.Path, "http://") {
-		if err := b.moveRemoteFile(info, path); err != nil {
+	if task != nil {
+		if task.err != nil {
+			return "", fmt.Errorf("failed to download %s file, err:%w", info.label(), task.err)
+		}
+		if err := b.moveRemoteFile(info, task.path, path); err != nil {
+			// The cached file may be broken, download it again next time.
+			_ = task.entry.remove()
 			return "", err
 		}
 		return path, nil
@@ -193,6 +263,9 @@ func (b *Sdk) preInstallSdk(info *Info, sdkDestPath string) (string, error) {
 
 func (b *Sdk) Uninstall(version Version) error {
 	label := b.label(version)
+	if _, ok := env.PathVersion(string(version)); ok {
+		return fmt.Errorf("%s is not installed by vfox, it can not be uninstalled", label)
+	}
 	if !b.checkExists(version) {
 		pterm.Printf("%s is not installed...\n", pterm.Red(label))
 		return fmt.Errorf("%s is not installed", label)
@@ -206,11 +279,13 @@ func (b *Sdk) Uninstall(version Version) error {
 		return err
 	}
 	pterm.Printf("Uninstalled %s successfully!\n", label)
+	b.sdkManager.refreshShims()
 	return nil
 }
 
-func (b *Sdk) Available() ([]*Package, error) {
-	return b.Plugin.Available()
+// Available returns the versions provided by the plugin, the args are passed to the Available hook as is.
+func (b *Sdk) Available(args ...string) ([]*Package, error) {
+	return b.Plugin.Available(args...)
 }
 
 func (b *Sdk) EnvKeys(version Version) (*env.Envs, error) {
@@ -255,13 +330,32 @@ func (b *Sdk) Use(version Version, scope UseScope) error {
 		scope = Global
 	}
 
+	if err := b.Pin(version, scope); err != nil {
+		return err
+	}
+	if !env.IsHookEnv() {
+		return shell.GetProcess().Open(os.Getppid())
+	}
+	return nil
+}
+
+// Pin records the version in the scope, the global environment is also updated for the Global scope.
+// Unlike Use, no new shell is opened.
+func (b *Sdk) Pin(version Version, scope UseScope) error {
 	logger.Debugf("use sdk version: %s\n", string(version))
+	version = b.resolveAlias(version)
+	if version == env.SystemVersion {
+		return b.pinSystem(scope)
+	}
 
 	version, err := b.PreUse(version, scope)
 	if err != nil {
 		return err
 	}
 
+	if !b.checkExists(version) {
+		version = b.resolveLocalVersion(version)
+	}
 	label := b.label(version)
 	if !b.checkExists(version) {
 		return fmt.Errorf("%s is not installed", label)
@@ -294,12 +388,127 @@ func (b *Sdk) Use(version Version, scope UseScope) error {
 		return err
 	}
 	pterm.Printf("Now using %s.\n", pterm.LightGreen(label))
-	if !env.IsHookEnv() {
-		return shell.GetProcess().Open(os.Getppid())
+	return nil
+}
+
+// pinSystem records the system version, so that the PATH entries of the sdk are dropped
+// and the binaries installed outside vfox win.
+func (b *Sdk) pinSystem(scope UseScope) error {
+	if scope == Global {
+		b.clearCurrentEnvConfig()
+		if err := b.sdkManager.EnvManager.Flush(); err != nil {
+			return err
+		}
+	}
+	b.sdkManager.Record.Add(b.Plugin.SdkName, env.SystemVersion)
+	if err := b.sdkManager.Record.Save(); err != nil {
+		return err
 	}
+	pterm.Printf("Now using %s.\n", pterm.LightGreen(b.label(env.SystemVersion)))
 	return nil
 }
 
+// resolveRemoteVersion resolves a user-defined alias or a fuzzy version against the available versions provided by the plugin.
+// The version is returned as is if it is exact, or if it can not be resolved,
+// so that the plugin can still handle it in PreInstall.
+func (b *Sdk) resolveRemoteVersion(version Version) Version {
+	version = b.resolveAlias(version)
+	if version == "" || util.IsExactVersion(string(version)) || b.checkExists(version) {
+		return version
+	}
+	available, err := b.Available()
+	if err != nil {
+		logger.Debugf("failed to resolve version %s, err: %s\n", version, err)
+		return version
+	}
+	resolved := resolveVersion(version, available)
+	if resolved == "" {
+		return version
+	}
+	if resolved != version {
+		pterm.Printf("Resolved %s to %s\n", b.label(version), pterm.LightGreen(b.label(resolved)))
+	}
+	return resolved
+}
+
+// resolveLocalVersion resolves a user-defined alias or a fuzzy version against the installed versions.
+func (b *Sdk) resolveLocalVersion(version Version) Version {
+	version = b.resolveAlias(version)
+	if b.checkExists(version) {
+		return version
+	}
+	var installed []*Package
+	for _, v := range b.List() {
+		installed = append(installed, &Package{Main: &Info{Name: b.Plugin.Name, Version: v}})
+	}
+	if resolved := resolveVersion(version, installed); resolved != "" {
+		return resolved
+	}
+	return version
+}
+
+// resolveVersion resolves a fuzzy version, such as `20`, `^20.1`, `latest`, or a tag like `lts`,
+// to the highest matching version of the candidates. An empty version is returned if nothing matches.
+func resolveVersion(version Version, candidates []*Package) Version {
+	var versions []string
+	for _, p := range candidates {
+		// an exact match always wins
+		if p.Main.Version == version {
+			return version
+		}
+		versions = append(versions, string(p.Main.Version))
+	}
+	if constraint, err := util.NewConstraint(string(version)); err == nil {
+		return Version(constraint.Highest(versions))
+	}
+	// Not a version constraint, try to match the aliases and tags provided by the plugin, such as `lts`.
+	var tagged []string
+	for _, p := range candidates {
+		if hasAlias(p.Aliases, string(version)) || hasTag(p.Main.Note, string(version)) {
+			tagged = append(tagged, string(p.Main.Version))
+		}
+	}
+	latest, _ := util.NewConstraint(util.LatestVersion)
+	return Version(latest.Highest(tagged))
+}
+
+// FilterPackages returns the packages matching the filter, which is either a version constraint,
+// such as `20` or `>=18 <20`, or an alias or tag provided by the plugin, such as `lts`.
+func FilterPackages(packages []*Package, filter string) []*Package {
+	if filter == "" {
+		return packages
+	}
+	constraint, err := util.NewConstraint(filter)
+	var result []*Package
+	for _, p := range packages {
+		if (err == nil && constraint.Check(string(p.Main.Version))) ||
+			hasAlias(p.Aliases, filter) || hasTag(p.Main.Note, filter) {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+func hasAlias(aliases []string, alias string) bool {
+	for _, a := range aliases {
+		if strings.EqualFold(a, alias) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasTag(note, tag string) bool {
+	for _, t := range strings.FieldsFunc(note, func(r rune) bool {
+		return r == ' ' || r == ',' || r == '(' || r == ')'
+	}) {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return strings.EqualFold(note, tag)
+}
+
 func (b *Sdk) List() []Version {
 	if !util.FileExists(b.InstallPath) {
 		return make([]Version, 0)
@@ -332,9 +541,38 @@ func (b *Sdk) getLocalSdkPackages() []*Package {
 	return infos
 }
 
+// Current returns the first installed version of the recorded versions, or the preferred one
+// if none of them is installed. The system version is always regarded as installed, and the
+// user-defined aliases and the fuzzy versions are resolved against the installed versions,
+// the same as install does for the recorded versions.
 func (b *Sdk) Current() Version {
-	version := b.sdkManager.Record.Export()[b.Plugin.SdkName]
-	return Version(version)
+	var versions []string
+	for _, v := range b.sdkManager.Record.Versions(b.Plugin.SdkName) {
+		if strings.HasPrefix(v, env.RefVersionPrefix) {
+			b.reportRef(Version(v))
+			continue
+		}
+		versions = append(versions, v)
+	}
+	for _, v := range versions {
+		version := b.resolveLocalVersion(Version(v))
+		if version == env.SystemVersion || b.checkExists(version) {
+			return version
+		}
+	}
+	if len(versions) == 0 {
+		return ""
+	}
+	return b.resolveAlias(Version(versions[0]))
+}
+
+// reportRef warns once that the versions built from a git ref are not supported, they are skipped.
+func (b *Sdk) reportRef(version Version) {
+	if b.refReported {
+		return
+	}
+	b.refReported = true
+	logger.Errorf("Warning: %s is not supported, a version built from a git ref is skipped\n", b.label(version))
 }
 
 func (b *Sdk) Close() {
@@ -362,6 +600,10 @@ func (b *Sdk) clearEnvConfig(version Version) {
 }
 
 func (b *Sdk) getLocalSdkPackage(version Version) (*Package, error) {
+	// A version in a custom directory is the install path itself.
+	if dir, ok := env.PathVersion(string(version)); ok {
+		return &Package{Main: &Info{Name: b.Plugin.Name, Version: version, Path: dir}}, nil
+	}
 	versionPath := b.VersionPath(version)
 	mainSdk := &Info{
 		Name:    b.Plugin.Name,
@@ -373,7 +615,14 @@ func (b *Sdk) getLocalSdkPackage(version Version) (*Package, error) {
 		return nil, err
 	}
 	for _, d := range dir {
-		if d.IsDir() {
+		isDir := d.IsDir()
+		// The linked versions are symlinks to the directories installed outside vfox.
+		if d.Type()&os.ModeSymlink != 0 {
+			if info, err := os.Stat(filepath.Join(versionPath, d.Name())); err == nil {
+				isDir = info.IsDir()
+			}
+		}
+		if isDir {
 			split := strings.SplitN(d.Name(), "-", 2)
 			name := split[0]
 			if name == b.Plugin.Name {
@@ -406,6 +655,9 @@ func (b *Sdk) getLocalSdkPackage(version Version) (*Package, error) {
 }
 
 func (b *Sdk) checkExists(version Version) bool {
+	if dir, ok := env.PathVersion(string(version)); ok {
+		return util.FileExists(dir)
+	}
 	return util.FileExists(b.VersionPath(version))
 }
 
@@ -414,66 +666,143 @@ func (b *Sdk) VersionPath(version Version) string {
 }
 
 func (b *Sdk) Download(u *url.URL) (string, error) {
-	req, err := http.NewRequest("GET", u.String(), nil)
+	entry, err := b.download(u, NoneChecksum, "Downloading...", os.Stderr)
 	if err != nil {
 		return "", err
 	}
+	return entry.FilePath(), nil
+}
+
+// download downloads the file into the DownloadCache and renders the progress bar into the writer.
+// A finished file with the same url and checksum is reused, and an interrupted download is resumed.
+func (b *Sdk) download(u *url.URL, checksum *Checksum, description string, writer io.Writer) (*CacheEntry, error) {
+	entry, unlock := b.sdkManager.DownloadCache.Entry(u, checksum)
+	defer unlock()
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	cached := entry.IsCompleted()
+	if cached {
+		// The checksum guarantees the content, otherwise ask the server whether the file has changed.
+		if entry.ChecksumValue != "" {
+			if checksum.verify(entry.FilePath()) == nil {
+				entry.touch()
+				_, _ = fmt.Fprintf(writer, "%s cached", description)
+				return entry, nil
+			}
+			// The cached file has been modified, download it again.
+			cached = false
+		} else if entry.ETag != "" {
+			req.Header.Set("If-None-Match", entry.ETag)
+		} else if entry.LastModified != "" {
+			req.Header.Set("If-Modified-Since", entry.LastModified)
+		}
+	}
+	var offset int64
+	if info, err := os.Stat(entry.partialPath()); err == nil && !cached && entry.validator() != "" {
+		offset = info.Size()
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		req.Header.Set("If-Range", entry.validator())
+	}
+
 	resp, err := b.sdkManager.httpClient().Do(req)
 	if err != nil {
 		var urlErr *url.Error
 		if errors.As(err, &urlErr) {
 			var netErr net.Error
 			if errors.As(urlErr.Err, &netErr) && netErr.Timeout() {
-				return "", errors.New("request timeout")
+				return nil, errors.New("request timeout")
 			}
 		}
-		return "", err
+		return nil, err
 	}
 
 	defer resp.Body.Close()
 
-	if resp.StatusCode == http.StatusNotFound {
-		return "", errors.New("source file not found")
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached:
+		entry.touch()
+		_, _ = fmt.Fprintf(writer, "%s cached", description)
+		return entry, nil
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		// The partial file is broken, start over next time.
+		_ = os.Remove(entry.partialPath())
+		return nil, errors.New("failed to resume the download, please try again")
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, errors.New("source file not found")
+	case resp.StatusCode >= http.StatusBadRequest:
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	flag := os.O_CREATE | os.O_WRONLY
+	if resp.StatusCode == http.StatusPartialContent {
+		flag |= os.O_APPEND
+	} else {
+		// The server does not support Range, or the file has changed.
+		offset = 0
+		flag |= os.O_TRUNC
 	}
-
-	err = os.MkdirAll(b.InstallPath, 0755)
+	// The digests are computed while downloading, so the file is never read twice.
+	hasher, err := checksum.newHasher()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
+	if offset > 0 {
+		if err = hashFile(hasher, entry.partialPath()); err != nil {
+			return nil, err
+		}
+	}
+	entry.Completed = false
+	entry.ETag = resp.Header.Get("ETag")
+	entry.LastModified = resp.Header.Get("Last-Modified")
+	entry.touch()
 
-	path := filepath.Join(b.InstallPath, filepath.Base(u.Path))
-
-	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0644)
+	f, err := os.OpenFile(entry.partialPath(), flag, 0644)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	defer f.Close()
 
-	bar := progressbar.NewOptions64(
-		resp.ContentLength,
-		progressbar.OptionSetWriter(os.Stderr),
-		progressbar.OptionEnableColorCodes(true),
-		progressbar.OptionShowBytes(true),
-		progressbar.OptionFullWidth(),
-		progressbar.OptionOnCompletion(func() {
-			fmt.Fprintf(os.Stderr, "\n")
-		}),
-		progressbar.OptionSetDescription("Downloading..."),
-		progressbar.OptionSetTheme(progressbar.Theme{
-			Saucer:        "[green]=[reset]",
-			SaucerHead:    "[green]>[reset]",
-			SaucerPadding: " ",
-			BarStart:      "[",
-			BarEnd:        "]",
-		}),
-	)
+	total := resp.ContentLength
+	if total >= 0 {
+		total += offset
+	}
+	bar := printer.NewDownloadBar(total, description, writer)
 	defer bar.Close()
-	_, err = io.Copy(io.MultiWriter(f, bar), resp.Body)
+	_ = bar.Set64(offset)
+	size, err := io.Copy(io.MultiWriter(f, bar, hasher), resp.Body)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	if err = f.Close(); err != nil {
+		return nil, err
 	}
-	return path, nil
+	if err = hasher.Verify(); err != nil {
+		// A broken file can not be resumed, download it again next time.
+		_ = os.Remove(entry.partialPath())
+		return nil, err
+	}
+	if err = os.Rename(entry.partialPath(), entry.FilePath()); err != nil {
+		return nil, err
+	}
+	entry.Size = offset + size
+	entry.Completed = true
+	entry.touch()
+	return entry, nil
+}
+
+// hashFile feeds the content of the file into the hasher.
+func hashFile(hasher io.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(hasher, f)
+	return err
 }
 
 func (b *Sdk) label(version Version) string {
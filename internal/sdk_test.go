@@ -0,0 +1,159 @@
+/*
+ *    Copyright 2024 Han Li and contributors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package internal
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/version-fox/vfox/internal/env"
+)
+
+func TestResolveVersion(t *testing.T) {
+	var candidates []*Package
+	for _, v := range []struct{ version, note string }{
+		{"21.6.2", ""},
+		{"20.11.1", "LTS"},
+		{"20.1.0", "LTS"},
+		{"18.19.0", "LTS"},
+		{"22.0.0-rc.1", ""},
+	} {
+		candidates = append(candidates, &Package{Main: &Info{Name: "nodejs", Version: Version(v.version), Note: v.note}})
+	}
+	tests := map[Version]Version{
+		"20":      "20.11.1",
+		"^20.1":   "20.11.1",
+		"~20.1":   "20.1.0",
+		"latest":  "21.6.2",
+		"lts":     "20.11.1",
+		"18.19.0": "18.19.0",
+		"19":      "",
+		"stable":  "",
+	}
+	for input, want := range tests {
+		if got := resolveVersion(input, candidates); got != want {
+			t.Errorf("resolveVersion(%s) = %s, want %s", input, got, want)
+		}
+	}
+}
+
+func TestFilterPackages(t *testing.T) {
+	var packages []*Package
+	for _, v := range []struct{ version, note string }{
+		{"21.6.2", ""},
+		{"20.11.1", "LTS"},
+		{"20.1.0", "LTS"},
+		{"18.19.0", "LTS"},
+	} {
+		packages = append(packages, &Package{Main: &Info{Name: "nodejs", Version: Version(v.version), Note: v.note}})
+	}
+	tests := map[string]int{
+		"":       4,
+		"20":     2,
+		">=20":   3,
+		"lts":    3,
+		"19":     0,
+		"stable": 0,
+	}
+	for filter, want := range tests {
+		if got := len(FilterPackages(packages, filter)); got != want {
+			t.Errorf("FilterPackages(%s) returns %d packages, want %d", filter, got, want)
+		}
+	}
+}
+
+func TestInstallMissing(t *testing.T) {
+	manager, _ := newTestManager(t)
+	// The available versions must not be looked up for a version installed already
+	plugin := `
+PLUGIN = { name = "nodejs", version = "0.0.1" }
+function PLUGIN:Available(ctx) error("unexpected call") end
+function PLUGIN:PreInstall(ctx) error("unexpected call") end
+function PLUGIN:EnvKeys(ctx) return {} end
+`
+	if err := os.WriteFile(filepath.Join(manager.PathMeta.PluginPath, "nodejs", "main.lua"), []byte(plugin), 0644); err != nil {
+		t.Fatal(err)
+	}
+	errs := manager.InstallMissing([]Arg{{Name: "nodejs", Version: "20"}, {Name: "nodejs", Version: "21"}})
+	if !errors.Is(errs[0], ErrAlreadyInstalled) {
+		t.Errorf("expected ErrAlreadyInstalled, got %v", errs[0])
+	}
+	if errs[1] == nil || errors.Is(errs[1], ErrAlreadyInstalled) {
+		t.Errorf("expected the plugin to be called, got %v", errs[1])
+	}
+}
+
+func TestCurrentResolvesRecord(t *testing.T) {
+	manager, _ := newTestManager(t)
+	path := installTestVersions(t, manager, "20.11.1", "21.0.0")[0]
+	manager.Record = env.NewReadonlyRecord(map[string]string{"nodejs": "20"}, nil)
+	sdk, err := manager.LookupSdk("nodejs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current := sdk.Current(); current != "20.11.1" {
+		t.Errorf("expected the recorded 20 to resolve to 20.11.1, got %s", current)
+	}
+	envs, err := manager.EnvKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(envs.Paths) != 1 || envs.Paths[0] != filepath.Join(path, "bin") {
+		t.Errorf("unexpected paths: %v", envs.Paths)
+	}
+}
+
+func TestCurrentCustomVersions(t *testing.T) {
+	manager, _ := newTestManager(t)
+	custom := filepath.Join(t.TempDir(), "nodejs-20.11.1")
+	if err := os.MkdirAll(custom, 0755); err != nil {
+		t.Fatal(err)
+	}
+	recordDir := t.TempDir()
+	content := "nodejs ref:v20.11.1 path:" + custom + "\n"
+	if err := os.WriteFile(filepath.Join(recordDir, ".tool-versions"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	record, err := env.NewRecord(recordDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	manager.Record = record
+	sdk, err := manager.LookupSdk("nodejs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The ref is skipped, and the custom directory is the install path
+	if current := sdk.Current(); current != Version("path:"+custom) {
+		t.Errorf("unexpected current version: %s", current)
+	}
+	envs, err := manager.EnvKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(envs.Paths) != 1 || envs.Paths[0] != filepath.Join(custom, "bin") {
+		t.Errorf("unexpected paths: %v", envs.Paths)
+	}
+	if _, err = sdk.prepareInstall("ref:v20.11.1"); err == nil {
+		t.Error("expected an error for installing a ref")
+	}
+	if err = sdk.Uninstall(sdk.Current()); err == nil || !sdk.checkExists(sdk.Current()) {
+		t.Errorf("expected the custom directory to be kept, err: %v", err)
+	}
+}
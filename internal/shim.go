@@ -0,0 +1,122 @@
+/*
+ *    Copyright 2024 Han Li and contributors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package internal
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+
+	"github.com/pterm/pterm"
+	"github.com/version-fox/vfox/internal/env"
+	"github.com/version-fox/vfox/internal/util"
+)
+
+// Reshim regenerates the shims of all binaries in the PATH entries of the installed sdk versions,
+// and returns the names of the shims.
+func (m *Manager) Reshim() ([]string, error) {
+	dir, err := os.ReadDir(m.PathMeta.PluginPath)
+	if err != nil {
+		return nil, fmt.Errorf("load sdks error: %w", err)
+	}
+	binaries := make(map[string]struct{})
+	for _, d := range dir {
+		if !d.IsDir() {
+			continue
+		}
+		sdk, err := m.LookupSdk(d.Name())
+		if err != nil {
+			continue
+		}
+		for _, version := range sdk.List() {
+			envs, err := sdk.EnvKeys(version)
+			if err != nil {
+				continue
+			}
+			for _, path := range envs.Paths {
+				for _, name := range executables(path) {
+					binaries[name] = struct{}{}
+				}
+			}
+		}
+	}
+	names := make([]string, 0, len(binaries))
+	for name := range binaries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	shimsPath := m.PathMeta.ShimsPath
+	if err = os.RemoveAll(shimsPath); err != nil {
+		return nil, fmt.Errorf("failed to clean shims, err: %w", err)
+	}
+	if err = os.MkdirAll(shimsPath, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create shims directory, err: %w", err)
+	}
+	for _, name := range names {
+		path := filepath.Join(shimsPath, shimFilename(name))
+		if err = os.WriteFile(path, []byte(shimContent(m.PathMeta.ExecutablePath, name)), 0755); err != nil {
+			return nil, fmt.Errorf("failed to write shim %s, err: %w", name, err)
+		}
+	}
+	return names, nil
+}
+
+// refreshShims regenerates the shims after installing or uninstalling a version,
+// only if the shims are in use.
+func (m *Manager) refreshShims() {
+	if m.PathMeta.ShimsPath == "" || !util.FileExists(m.PathMeta.ShimsPath) {
+		return
+	}
+	if _, err := m.Reshim(); err != nil {
+		pterm.Printf("Failed to regenerate shims, err: %s\n", err)
+	}
+}
+
+// ShimTarget looks up the binary of a shim in the sdk versions recorded in the project, session
+// and global records like Which, and returns the binary with the environment of these versions.
+// The binary in PATH is used if none of the versions provides it.
+func (m *Manager) ShimTarget(name string) (string, *env.Envs, error) {
+	envs, err := m.EnvKeys()
+	if err != nil {
+		return "", nil, err
+	}
+	if bin, _, _, err := m.Which(name); err == nil {
+		return bin, envs, nil
+	}
+	// Fall back to the binary installed outside vfox, such as for the system version.
+	if bin := m.lookupBinary(filepath.SplitList(os.Getenv("PATH")), name); bin != "" {
+		return bin, envs, nil
+	}
+	return "", nil, fmt.Errorf("no version providing %s is set, please use `vfox use` to set one", name)
+}
+
+// lookupBinary returns the first executable with the name in the paths, the shims are skipped.
+func (m *Manager) lookupBinary(paths []string, name string) string {
+	for _, path := range paths {
+		// Never resolve to a shim, which would call itself endlessly.
+		if filepath.Clean(path) == filepath.Clean(m.PathMeta.ShimsPath) {
+			continue
+		}
+		if bin, err := exec.LookPath(filepath.Join(path, name)); err == nil {
+			return bin
+		}
+	}
+	return ""
+}
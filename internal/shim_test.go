@@ -0,0 +1,128 @@
+/*
+ *    Copyright 2024 Han Li and contributors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/version-fox/vfox/internal/env"
+)
+
+func TestReshim(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shims are cmd scripts on windows")
+	}
+	manager, binDir := newTestManager(t)
+
+	names, err := manager.Reshim()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(names, ",") != "node,npm" {
+		t.Fatalf("unexpected shims: %v", names)
+	}
+	content, err := os.ReadFile(filepath.Join(manager.PathMeta.ShimsPath, "node"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "exec '/usr/local/bin/vfox' shim 'node' \"$@\"") {
+		t.Errorf("unexpected shim content: %s", content)
+	}
+
+	bin, envs, err := manager.ShimTarget("npm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bin != filepath.Join(binDir, "npm") || len(envs.Paths) != 1 {
+		t.Errorf("unexpected shim target: %s, %v", bin, envs.Paths)
+	}
+	if _, _, err = manager.ShimTarget("README"); err == nil {
+		t.Error("expected an error for a file which is not executable")
+	}
+}
+
+func TestWhichAndWhere(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("executables need an extension on windows")
+	}
+	manager, binDir := newTestManager(t)
+
+	bin, sdk, version, err := manager.Which("node")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bin != filepath.Join(binDir, "node") || sdk.Plugin.SdkName != "nodejs" || version != "20.0.0" {
+		t.Errorf("unexpected binary: %s, %s@%s", bin, sdk.Plugin.SdkName, version)
+	}
+	if _, _, _, err = manager.Which("python"); err == nil {
+		t.Error("expected an error for a missing binary")
+	}
+
+	for _, version := range []Version{"", "20", "20.0.0"} {
+		path, err := sdk.Where(version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if path != filepath.Dir(binDir) {
+			t.Errorf("unexpected path of %q: %s", version, path)
+		}
+	}
+	if _, err = sdk.Where("19"); err == nil {
+		t.Error("expected an error for a version not installed")
+	}
+}
+
+func TestShimTargetOrder(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("executables need an extension on windows")
+	}
+	manager, _ := newTestManager(t)
+	// bun sorts before nodejs, and provides a node binary too
+	pluginDir := filepath.Join(manager.PathMeta.PluginPath, "bun")
+	binDir := filepath.Join(manager.PathMeta.SdkCachePath, "bun", "v-1.0.0", "bun-1.0.0", "bin")
+	for _, path := range []string{pluginDir, binDir} {
+		if err := os.MkdirAll(path, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	plugin := strings.Replace(testPlugin, `name = "nodejs"`, `name = "bun"`, 1)
+	if err := os.WriteFile(filepath.Join(pluginDir, "main.lua"), []byte(plugin), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(binDir, "node"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	manager.Record = env.NewReadonlyRecord(map[string]string{"nodejs": "20.0.0", "bun": "1.0.0"}, nil)
+
+	which, _, _, err := manager.Which("node")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		bin, envs, err := manager.ShimTarget("node")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bin != which || bin != filepath.Join(binDir, "node") || envs.Paths[0] != binDir {
+			t.Fatalf("unexpected shim target: %s, %v", bin, envs.Paths)
+		}
+	}
+}
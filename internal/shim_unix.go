@@ -0,0 +1,58 @@
+//go:build !windows
+
+/*
+ *    Copyright 2024 Han Li and contributors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// executables returns the names of the executable files in the directory.
+func executables(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, entry := range entries {
+		// Follow the symlinks, which are common in the bin directories.
+		info, err := os.Stat(filepath.Join(dir, entry.Name()))
+		if err != nil || info.IsDir() || info.Mode()&0111 == 0 {
+			continue
+		}
+		names = append(names, entry.Name())
+	}
+	return names
+}
+
+func shimFilename(name string) string {
+	return name
+}
+
+func shimContent(exePath, name string) string {
+	return fmt.Sprintf("#!/bin/sh\n# Generated by vfox, do not edit.\nexec %s shim %s \"$@\"\n",
+		shellQuote(exePath), shellQuote(name))
+}
+
+// shellQuote quotes the string for the POSIX sh, which has no $'...' quoting.
+func shellQuote(str string) string {
+	return "'" + strings.ReplaceAll(str, "'", `'\''`) + "'"
+}
@@ -0,0 +1,59 @@
+//go:build windows
+
+/*
+ *    Copyright 2024 Han Li and contributors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// executableExts are the extensions which can be run by the cmd.exe shims.
+var executableExts = []string{".exe", ".cmd", ".bat", ".com"}
+
+// executables returns the names of the executable files in the directory, without extensions.
+func executables(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		ext := filepath.Ext(entry.Name())
+		for _, e := range executableExts {
+			if strings.EqualFold(ext, e) {
+				names = append(names, strings.TrimSuffix(entry.Name(), ext))
+				break
+			}
+		}
+	}
+	return names
+}
+
+func shimFilename(name string) string {
+	return name + ".cmd"
+}
+
+func shimContent(exePath, name string) string {
+	return fmt.Sprintf("@echo off\r\nrem Generated by vfox, do not edit.\r\n\"%s\" shim \"%s\" %%*\r\n", exePath, name)
+}
@@ -0,0 +1,325 @@
+/*
+ *    Copyright 2024 Han Li and contributors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package internal
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"golang.org/x/crypto/blake2b"
+)
+
+const (
+	GpgSignature      = "gpg"
+	MinisignSignature = "minisign"
+	CosignSignature   = "cosign"
+)
+
+// Signature is a detached signature of a sdk file.
+type Signature struct {
+	// Type is one of gpg, minisign and cosign, it is detected from the signature if empty.
+	Type string
+	Url  string
+	// PublicKey is the name of a key file or a key directory under the keys directory.
+	PublicKey string
+}
+
+type LuaSignature struct {
+	Url       string `luai:"signature_url"`
+	Type      string `luai:"signature_type"`
+	PublicKey string `luai:"public_key"`
+}
+
+func (s *LuaSignature) Signature() *Signature {
+	if s.Url == "" {
+		return nil
+	}
+	return &Signature{
+		Type:      strings.ToLower(s.Type),
+		Url:       s.Url,
+		PublicKey: s.PublicKey,
+	}
+}
+
+// publicKeys loads the trusted public keys from the keys directory, a key provided by the plugin itself
+// is never trusted. If the plugin does not reference a key, the key file or the key directory named
+// after the plugin is used.
+func (s *Signature) publicKeys(keysPath, name string) ([][]byte, error) {
+	ref := s.PublicKey
+	if ref == "" {
+		ref = name
+	} else if !filepath.IsLocal(ref) {
+		return nil, fmt.Errorf("public key %q is not the name of a key file under %s", ref, keysPath)
+	}
+	path := filepath.Join(keysPath, ref)
+	info, err := os.Stat(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+		if s.PublicKey == "" {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("public key %s is not found in %s", ref, keysPath)
+	}
+	if !info.IsDir() {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return [][]byte{content}, nil
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	var keys [][]byte
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(path, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, content)
+	}
+	return keys, nil
+}
+
+// detectType detects the type of the signature by its content.
+func (s *Signature) detectType(signature []byte) string {
+	if s.Type != "" {
+		return s.Type
+	}
+	content := bytes.TrimSpace(signature)
+	switch {
+	case bytes.HasPrefix(content, []byte("untrusted comment:")):
+		return MinisignSignature
+	case bytes.HasPrefix(content, []byte("{")):
+		return CosignSignature
+	case bytes.HasPrefix(content, []byte("-----BEGIN PGP")):
+		return GpgSignature
+	}
+	if _, err := base64.StdEncoding.DecodeString(string(content)); err == nil {
+		return CosignSignature
+	}
+	return GpgSignature
+}
+
+// verify checks the signature of the file with the trusted keys, the file is read as a stream.
+func (s *Signature) verify(path string, signature []byte, keys [][]byte) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	typ := s.detectType(signature)
+	switch typ {
+	case GpgSignature:
+		err = verifyGpg(f, signature, keys)
+	case MinisignSignature:
+		err = verifyMinisign(f, signature, keys)
+	case CosignSignature:
+		err = verifyCosign(f, signature, keys)
+	default:
+		return fmt.Errorf("unsupported signature type: %s", typ)
+	}
+	if err != nil {
+		return fmt.Errorf("%s signature verification failed: %w", typ, err)
+	}
+	return nil
+}
+
+func verifyGpg(file io.Reader, signature []byte, keys [][]byte) error {
+	var keyring openpgp.EntityList
+	for _, key := range keys {
+		var (
+			entities openpgp.EntityList
+			err      error
+		)
+		if bytes.Contains(key, []byte("-----BEGIN PGP")) {
+			entities, err = openpgp.ReadArmoredKeyRing(bytes.NewReader(key))
+		} else {
+			entities, err = openpgp.ReadKeyRing(bytes.NewReader(key))
+		}
+		if err != nil {
+			continue
+		}
+		keyring = append(keyring, entities...)
+	}
+	if len(keyring) == 0 {
+		return errors.New("no valid gpg public key")
+	}
+	var err error
+	if bytes.Contains(signature, []byte("-----BEGIN PGP")) {
+		_, err = openpgp.CheckArmoredDetachedSignature(keyring, file, bytes.NewReader(signature), nil)
+	} else {
+		_, err = openpgp.CheckDetachedSignature(keyring, file, bytes.NewReader(signature), nil)
+	}
+	return err
+}
+
+// minisignKey is the ed25519 public key of minisign, prefixed by the key id.
+type minisignKey struct {
+	id  []byte
+	key ed25519.PublicKey
+}
+
+// minisignLines returns the lines of a minisign file, skipping the untrusted comments.
+func minisignLines(content []byte) []string {
+	var lines []string
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "untrusted comment:") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func parseMinisignKey(content []byte) (*minisignKey, error) {
+	lines := minisignLines(content)
+	if len(lines) == 0 {
+		return nil, errors.New("empty minisign public key")
+	}
+	data, err := base64.StdEncoding.DecodeString(lines[0])
+	if err != nil || len(data) != 42 || string(data[:2]) != "Ed" {
+		return nil, errors.New("invalid minisign public key")
+	}
+	return &minisignKey{id: data[2:10], key: data[10:]}, nil
+}
+
+func verifyMinisign(file io.Reader, signature []byte, keys [][]byte) error {
+	lines := minisignLines(signature)
+	if len(lines) < 3 || !strings.HasPrefix(lines[1], "trusted comment:") {
+		return errors.New("invalid minisign signature")
+	}
+	sig, err := base64.StdEncoding.DecodeString(lines[0])
+	if err != nil || len(sig) != 74 {
+		return errors.New("invalid minisign signature")
+	}
+	globalSig, err := base64.StdEncoding.DecodeString(lines[2])
+	if err != nil || len(globalSig) != ed25519.SignatureSize {
+		return errors.New("invalid minisign signature")
+	}
+	algorithm, keyId, sigBytes := string(sig[:2]), sig[2:10], sig[10:]
+
+	var message []byte
+	switch algorithm {
+	case "ED":
+		// the file is prehashed with blake2b-512
+		h, _ := blake2b.New512(nil)
+		if _, err = io.Copy(h, file); err != nil {
+			return err
+		}
+		message = h.Sum(nil)
+	case "Ed":
+		if message, err = io.ReadAll(file); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported minisign algorithm: %s", algorithm)
+	}
+
+	trustedComment := strings.TrimPrefix(lines[1], "trusted comment:")
+	trustedComment = strings.TrimPrefix(trustedComment, " ")
+	for _, content := range keys {
+		key, err := parseMinisignKey(content)
+		if err != nil || !bytes.Equal(key.id, keyId) {
+			continue
+		}
+		if !ed25519.Verify(key.key, message, sigBytes) {
+			return errors.New("invalid signature")
+		}
+		global := append(append([]byte{}, sigBytes...), trustedComment...)
+		if !ed25519.Verify(key.key, global, globalSig) {
+			return errors.New("invalid trusted comment")
+		}
+		return nil
+	}
+	return errors.New("no public key matches the key id of the signature")
+}
+
+// cosignBundle is the signature bundle of `cosign sign-blob --bundle`,
+// both the legacy format and the sigstore bundle format are supported.
+type cosignBundle struct {
+	Base64Signature  string `json:"base64Signature"`
+	MessageSignature struct {
+		Signature string `json:"signature"`
+	} `json:"messageSignature"`
+}
+
+func verifyCosign(file io.Reader, signature []byte, keys [][]byte) error {
+	encoded := string(bytes.TrimSpace(signature))
+	if strings.HasPrefix(encoded, "{") {
+		var bundle cosignBundle
+		if err := json.Unmarshal(signature, &bundle); err != nil {
+			return fmt.Errorf("invalid cosign bundle: %w", err)
+		}
+		encoded = bundle.Base64Signature
+		if encoded == "" {
+			encoded = bundle.MessageSignature.Signature
+		}
+	}
+	sig, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(sig) == 0 {
+		return errors.New("invalid cosign signature")
+	}
+	h := sha256.New()
+	if _, err = io.Copy(h, file); err != nil {
+		return err
+	}
+	digest := h.Sum(nil)
+	for _, content := range keys {
+		block, _ := pem.Decode(content)
+		if block == nil {
+			continue
+		}
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			continue
+		}
+		switch k := key.(type) {
+		case *ecdsa.PublicKey:
+			if ecdsa.VerifyASN1(k, digest, sig) {
+				return nil
+			}
+		case *rsa.PublicKey:
+			if rsa.VerifyPKCS1v15(k, crypto.SHA256, digest, sig) == nil {
+				return nil
+			}
+		}
+	}
+	return errors.New("invalid signature")
+}
@@ -0,0 +1,182 @@
+/*
+ *    Copyright 2024 Han Li and contributors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package internal
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"golang.org/x/crypto/blake2b"
+)
+
+func writeSdkFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "sdk.tar.gz")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestVerifyGpgSignature(t *testing.T) {
+	path := writeSdkFile(t, "vfox")
+	entity, err := openpgp.NewEntity("vfox", "", "vfox@example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature := &bytes.Buffer{}
+	if err = openpgp.ArmoredDetachSign(signature, entity, bytes.NewReader([]byte("vfox")), nil); err != nil {
+		t.Fatal(err)
+	}
+	key := &bytes.Buffer{}
+	if err = entity.Serialize(key); err != nil {
+		t.Fatal(err)
+	}
+
+	s := &Signature{}
+	if err = s.verify(path, signature.Bytes(), [][]byte{key.Bytes()}); err != nil {
+		t.Fatal(err)
+	}
+	tampered := writeSdkFile(t, "vfox!")
+	if err = s.verify(tampered, signature.Bytes(), [][]byte{key.Bytes()}); err == nil {
+		t.Errorf("expected the tampered file to be rejected")
+	}
+}
+
+func TestVerifyMinisignSignature(t *testing.T) {
+	path := writeSdkFile(t, "vfox")
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyId := []byte("12345678")
+	key := "untrusted comment: minisign public key\n" +
+		base64.StdEncoding.EncodeToString(append(append([]byte("Ed"), keyId...), public...)) + "\n"
+
+	digest := blake2b.Sum512([]byte("vfox"))
+	sig := ed25519.Sign(private, digest[:])
+	comment := "timestamp:1700000000"
+	globalSig := ed25519.Sign(private, append(append([]byte{}, sig...), comment...))
+	signature := "untrusted comment: signature\n" +
+		base64.StdEncoding.EncodeToString(append(append([]byte("ED"), keyId...), sig...)) + "\n" +
+		"trusted comment: " + comment + "\n" +
+		base64.StdEncoding.EncodeToString(globalSig) + "\n"
+
+	s := &Signature{}
+	if s.detectType([]byte(signature)) != MinisignSignature {
+		t.Fatalf("expected a minisign signature")
+	}
+	if err = s.verify(path, []byte(signature), [][]byte{[]byte(key)}); err != nil {
+		t.Fatal(err)
+	}
+	forged := bytes.Replace([]byte(signature), []byte(comment), []byte("timestamp:0"), 1)
+	if err = s.verify(path, forged, [][]byte{[]byte(key)}); err == nil {
+		t.Errorf("expected the forged trusted comment to be rejected")
+	}
+}
+
+func TestVerifyCosignSignature(t *testing.T) {
+	path := writeSdkFile(t, "vfox")
+	private, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&private.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	digest := sha256.Sum256([]byte("vfox"))
+	sig, err := ecdsa.SignASN1(rand.Reader, private, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &Signature{}
+	for _, signature := range []string{
+		base64.StdEncoding.EncodeToString(sig),
+		`{"base64Signature":"` + base64.StdEncoding.EncodeToString(sig) + `"}`,
+	} {
+		if err = s.verify(path, []byte(signature), [][]byte{key}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	other, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	der, _ = x509.MarshalPKIXPublicKey(&other.PublicKey)
+	otherKey := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	if err = s.verify(path, []byte(base64.StdEncoding.EncodeToString(sig)), [][]byte{otherKey}); err == nil {
+		t.Errorf("expected an untrusted key to be rejected")
+	}
+}
+
+func TestSignaturePublicKeys(t *testing.T) {
+	keysPath := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(keysPath, "nodejs"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a.asc", "b.asc"} {
+		if err := os.WriteFile(filepath.Join(keysPath, "nodejs", name), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(keysPath, "golang.asc"), []byte("golang"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		publicKey string
+		expected  int
+	}{
+		{"nodejs", "", 2},
+		{"java", "", 0},
+		{"go", "golang.asc", 1},
+		// The plugin can not provide a key itself
+		{"zig", "RWSGOq2NVecA2UPNdBUZykf1CCb147pkmdtYxgb3Ti+JO/wCYvhbAb/U", -1},
+		{"deno", "-----BEGIN PGP PUBLIC KEY BLOCK-----\nxxx\n-----END PGP PUBLIC KEY BLOCK-----", -1},
+		{"bun", "../bun.asc", -1},
+		{"bun", "/etc/bun.asc", -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Signature{PublicKey: tt.publicKey}
+			keys, err := s.publicKeys(keysPath, tt.name)
+			if tt.expected < 0 {
+				if err == nil {
+					t.Errorf("expected an error for public key %q", tt.publicKey)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(keys) != tt.expected {
+				t.Errorf("expected %d keys, got %d", tt.expected, len(keys))
+			}
+		})
+	}
+}
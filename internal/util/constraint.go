@@ -0,0 +1,251 @@
+/*
+ *    Copyright 2024 Han Li and contributors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package util
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// LatestVersion matches the highest stable version.
+const LatestVersion = "latest"
+
+// operators are sorted so that the longer ones are matched first.
+var operators = []string{">=", "<=", "!=", ">", "<", "=", "~", "^"}
+
+type comparator func(version string) bool
+
+// Constraint is a version range, such as `20`, `~1.2.3`, `^20.1`, `>=1.2 <2`, `1.x || 2.x` or `latest`.
+//
+// Comparators separated by spaces or commas must all match, while the groups separated by `||`
+// are alternatives. A partial version like `20` or `20.x` matches every version with this prefix.
+// Unstable versions (see IsPrerelease) only match if the constraint mentions a pre-release itself.
+type Constraint struct {
+	raw               string
+	groups            [][]comparator
+	includePrerelease bool
+}
+
+// NewConstraint parses a version constraint.
+func NewConstraint(s string) (*Constraint, error) {
+	raw := strings.TrimSpace(s)
+	c := &Constraint{raw: raw}
+	if raw == "" || raw == "*" || strings.EqualFold(raw, LatestVersion) {
+		c.groups = [][]comparator{{func(string) bool { return true }}}
+		return c, nil
+	}
+	for _, group := range strings.Split(raw, "||") {
+		fields := strings.FieldsFunc(group, func(r rune) bool {
+			return r == ' ' || r == ',' || r == '\t'
+		})
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("invalid version constraint: %s", raw)
+		}
+		var comparators []comparator
+		for i := 0; i < len(fields); i++ {
+			field := fields[i]
+			// allow a space between the operator and the version, e.g. `>= 1.2`
+			if isOperator(field) && i+1 < len(fields) {
+				i++
+				field += fields[i]
+			}
+			cmp, pre, err := parseComparator(field)
+			if err != nil {
+				return nil, fmt.Errorf("invalid version constraint: %s, %w", raw, err)
+			}
+			if pre {
+				c.includePrerelease = true
+			}
+			comparators = append(comparators, cmp)
+		}
+		c.groups = append(c.groups, comparators)
+	}
+	return c, nil
+}
+
+// Check reports whether the version satisfies the constraint.
+func (c *Constraint) Check(version string) bool {
+	if !c.includePrerelease && IsPrerelease(version) {
+		return false
+	}
+	for _, group := range c.groups {
+		matched := true
+		for _, cmp := range group {
+			if !cmp(version) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Highest returns the highest version that satisfies the constraint,
+// or an empty string if none matches.
+func (c *Constraint) Highest(versions []string) string {
+	var highest string
+	for _, v := range versions {
+		if !c.Check(v) {
+			continue
+		}
+		if highest == "" || CompareVersion(v, highest) > 0 {
+			highest = v
+		}
+	}
+	return highest
+}
+
+func (c *Constraint) String() string {
+	return c.raw
+}
+
+func isOperator(s string) bool {
+	for _, op := range operators {
+		if s == op {
+			return true
+		}
+	}
+	return false
+}
+
+// parseComparator parses a single comparator, the second returned value reports
+// whether it refers to a pre-release version.
+func parseComparator(s string) (comparator, bool, error) {
+	op := ""
+	for _, o := range operators {
+		if strings.HasPrefix(s, o) {
+			op = o
+			break
+		}
+	}
+	v := strings.TrimPrefix(strings.TrimPrefix(strings.TrimPrefix(s, op), "v"), "V")
+	if v == "" {
+		return nil, false, fmt.Errorf("missing version after %q", op)
+	}
+	if c := v[0]; !(c >= '0' && c <= '9') && c != '*' && c != 'x' && c != 'X' {
+		return nil, false, fmt.Errorf("%q is not a version", s)
+	}
+	parts := parseVersionParts(v)
+	pre := parts.prerelease != ""
+
+	// Wildcards truncate the version, `1.x.x` is the same as `1`.
+	var given []string
+	for _, seg := range parts.segments {
+		if seg == "*" || seg == "x" || seg == "X" {
+			break
+		}
+		if seg == "" {
+			return nil, false, fmt.Errorf("%q is not a version", s)
+		}
+		given = append(given, seg)
+	}
+	// A partial version which can not be incremented is treated as a complete one.
+	partial := len(given) < 3 && !pre
+	if partial && len(given) > 0 {
+		if _, err := strconv.Atoi(given[len(given)-1]); err != nil {
+			partial = false
+		}
+	}
+
+	if len(given) == 0 {
+		// `*`, `x`: any version
+		if op == "!=" || op == "<" || op == ">" {
+			return func(string) bool { return false }, pre, nil
+		}
+		return func(string) bool { return true }, pre, nil
+	}
+
+	full := v
+	if partial {
+		full = strings.Join(given, ".")
+	}
+	lower := strings.Join(given, ".")
+	upper := incrementAt(given, len(given)-1)
+
+	ge := func(bound string) comparator {
+		return func(version string) bool { return CompareVersion(version, bound) >= 0 }
+	}
+	lt := func(bound string) comparator {
+		return func(version string) bool { return compareRelease(version, bound) < 0 }
+	}
+	and := func(a, b comparator) comparator {
+		return func(version string) bool { return a(version) && b(version) }
+	}
+
+	switch op {
+	case ">=":
+		return ge(lower), pre, nil
+	case ">":
+		if partial {
+			return ge(upper), pre, nil
+		}
+		return func(version string) bool { return CompareVersion(version, full) > 0 }, pre, nil
+	case "<":
+		return lt(lower), pre, nil
+	case "<=":
+		if partial {
+			return lt(upper), pre, nil
+		}
+		return func(version string) bool { return CompareVersion(version, full) <= 0 }, pre, nil
+	case "~":
+		// ~1.2.3 := >=1.2.3 <1.3.0, ~1.2 := >=1.2 <1.3, ~1 := >=1 <2
+		idx := 1
+		if len(given) == 1 {
+			idx = 0
+		}
+		return and(ge(full), lt(incrementAt(given, idx))), pre, nil
+	case "^":
+		// ^1.2.3 := >=1.2.3 <2.0.0, ^0.2.3 := >=0.2.3 <0.3.0, ^0.0.3 := >=0.0.3 <0.0.4
+		idx := 0
+		for idx < len(given)-1 && given[idx] == "0" {
+			idx++
+		}
+		return and(ge(full), lt(incrementAt(given, idx))), pre, nil
+	case "!=":
+		if partial {
+			eq := and(ge(lower), lt(upper))
+			return func(version string) bool { return !eq(version) }, pre, nil
+		}
+		return func(version string) bool { return CompareVersion(version, full) != 0 }, pre, nil
+	default:
+		// `=` or no operator
+		if partial {
+			return and(ge(lower), lt(upper)), pre, nil
+		}
+		return func(version string) bool { return CompareVersion(version, full) == 0 }, pre, nil
+	}
+}
+
+// incrementAt increments the segment at index i and drops the following ones, e.g. (1.2.3, 1) -> 1.3
+func incrementAt(segments []string, i int) string {
+	if i >= len(segments) {
+		i = len(segments) - 1
+	}
+	n, _, _ := splitSegment(segments[i])
+	result := append([]string{}, segments[:i]...)
+	return strings.Join(append(result, strconv.Itoa(n+1)), ".")
+}
+
+// compareRelease compares two versions without their pre-release parts,
+// so that the pre-releases of an exclusive upper bound are excluded as well, e.g. 2.0.0-rc.1 is not lower than 2.
+func compareRelease(v1, v2 string) int {
+	return compareSegments(parseVersionParts(v1).segments, parseVersionParts(v2).segments)
+}
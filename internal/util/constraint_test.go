@@ -0,0 +1,85 @@
+/*
+ *    Copyright 2024 Han Li and contributors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package util
+
+import "testing"
+
+func TestConstraint(t *testing.T) {
+	versions := []string{"16.20.2", "18.0.0", "18.19.0", "20.0.0", "20.1.0", "20.11.1", "21.0.0-rc.1", "21.6.2", "22.0.0-nightly"}
+	tests := []struct {
+		constraint string
+		want       string
+	}{
+		{"latest", "21.6.2"},
+		{"*", "21.6.2"},
+		{"20", "20.11.1"},
+		{"20.x", "20.11.1"},
+		{"18.19", "18.19.0"},
+		{"=20.1.0", "20.1.0"},
+		{"~20.1", "20.1.0"},
+		{"~20", "20.11.1"},
+		{"^20.1", "20.11.1"},
+		{"^18.0.0", "18.19.0"},
+		{">=18 <20", "18.19.0"},
+		{">= 18, < 20", "18.19.0"},
+		{">20", "21.6.2"},
+		{"<=20", "20.11.1"},
+		{"<18", "16.20.2"},
+		{"16 || 18", "18.19.0"},
+		{"!=21", "20.11.1"},
+		{"^21.0.0-rc.1", "21.6.2"},
+		{"22", ""},
+		{"22.0.0-nightly", "22.0.0-nightly"},
+	}
+	for _, test := range tests {
+		t.Run(test.constraint, func(t *testing.T) {
+			c, err := NewConstraint(test.constraint)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := c.Highest(versions); got != test.want {
+				t.Errorf("Highest() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestInvalidConstraint(t *testing.T) {
+	for _, s := range []string{"lts", ">=", "stable", "1.2 || "} {
+		if _, err := NewConstraint(s); err == nil {
+			t.Errorf("expected %q to be invalid", s)
+		}
+	}
+}
+
+func TestIsExactVersion(t *testing.T) {
+	tests := map[string]bool{
+		"1.2.3":      true,
+		"v20.11.1":   true,
+		"17.0.2+8":   true,
+		"17.0.2-tem": true,
+		"20":         false,
+		"20.1":       false,
+		"^20.1.0":    false,
+		"latest":     false,
+	}
+	for v, want := range tests {
+		if got := IsExactVersion(v); got != want {
+			t.Errorf("IsExactVersion(%q) = %v, want %v", v, got, want)
+		}
+	}
+}
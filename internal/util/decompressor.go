@@ -20,6 +20,7 @@ import (
 	"archive/tar"
 	"archive/zip"
 	"compress/gzip"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
@@ -30,29 +31,63 @@ import (
 
 type Decompressor interface {
 	Decompress(dest string) error
+	// List returns the names of the entries in the archive.
+	List() ([]string, error)
+}
+
+// DefaultStrip removes the top-level directory of the archive, see DecompressOptions.Strip.
+const DefaultStrip = -1
+
+// DecompressOptions customizes the decompression of an archive.
+type DecompressOptions struct {
+	// Format is one of tar.gz, tgz, tar.xz, tar and zip, it is detected from the file extension if empty,
+	// except for tar.
+	Format string `yaml:"format,omitempty"`
+	// Strip is the number of leading path components removed from the entries, like `tar --strip-components`,
+	// the entries with no more components are skipped. DefaultStrip removes the top-level directory.
+	Strip int `yaml:"strip"`
 }
 
 type symlink struct {
 	oldname, newname string
 }
 
-type GzipTarDecompressor struct {
-	src string
+// stripComponents removes the leading components of the entry name, false is returned if nothing is left.
+// With DefaultStrip the top-level directory itself maps to dest, so it is skipped as well.
+func stripComponents(name string, dir bool, strip int) (string, bool) {
+	parts := strings.Split(strings.Trim(name, "/"), "/")
+	if strip == DefaultStrip {
+		if len(parts) > 1 {
+			return strings.Join(parts[1:], "/"), true
+		}
+		return parts[0], !dir
+	}
+	if len(parts) <= strip {
+		return "", false
+	}
+	return strings.Join(parts[strip:], "/"), true
 }
 
-func (g *GzipTarDecompressor) Decompress(dest string) error {
-	file, err := os.Open(g.src)
-	if err != nil {
-		return err
+// safeJoin joins the entry name to dest, it fails if the entry would be written outside of dest.
+func safeJoin(dest, name string) (string, error) {
+	target := filepath.Join(dest, name)
+	if rel, err := filepath.Rel(dest, target); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("illegal file path in the archive: %s", name)
 	}
-	defer file.Close()
-	gzr, err := gzip.NewReader(file)
+	return target, nil
+}
+
+// openTar opens the tar stream of the archive, the returned closer must be called when done.
+type openTar func(src string) (io.Reader, io.Closer, error)
+
+func decompressTar(src, dest string, strip int, open openTar) error {
+	r, closer, err := open(src)
 	if err != nil {
 		return err
 	}
-	defer gzr.Close()
+	defer closer.Close()
 
-	tr := tar.NewReader(gzr)
+	tr := tar.NewReader(r)
 	var symlinks []symlink
 loop:
 	for {
@@ -65,16 +100,14 @@ loop:
 		case header == nil:
 			continue
 		}
-		// Split the file name into a slice
-		parts := strings.Split(header.Name, "/")
-		if len(parts) > 1 {
-			// Remove the first element
-			parts = parts[1:]
+		fname, ok := stripComponents(header.Name, header.Typeflag == tar.TypeDir, strip)
+		if !ok {
+			continue
+		}
+		target, err := safeJoin(dest, fname)
+		if err != nil {
+			return err
 		}
-		// Join the remaining elements to get the new file name
-		fname := strings.Join(parts, "/")
-
-		target := filepath.Join(dest, fname)
 		switch header.Typeflag {
 		case tar.TypeDir:
 			if _, err := os.Stat(target); err != nil {
@@ -84,12 +117,13 @@ loop:
 			}
 		case tar.TypeReg:
 			_ = os.MkdirAll(filepath.Dir(target), 0755)
-			f, err := os.OpenFile(target, os.O_CREATE|os.O_RDWR, os.FileMode(header.Mode))
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_RDWR|os.O_TRUNC, os.FileMode(header.Mode))
 			if err != nil {
 				return err
 			}
 
 			if _, err := io.Copy(f, tr); err != nil {
+				f.Close()
 				return err
 			}
 
@@ -106,88 +140,120 @@ loop:
 	return nil
 }
 
-type XZTarDecompressor struct {
-	src string
-}
-
-func (g *XZTarDecompressor) Decompress(dest string) error {
-	file, err := os.Open(g.src)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-	gzr, err := xz.NewReader(file)
+func listTar(src string, open openTar) ([]string, error) {
+	r, closer, err := open(src)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	defer closer.Close()
 
-	tr := tar.NewReader(gzr)
-	var symlinks []symlink
-loop:
+	tr := tar.NewReader(r)
+	var names []string
 	for {
 		header, err := tr.Next()
-		switch {
-		case err == io.EOF:
-			break loop
-		case err != nil:
-			return err
-		case header == nil:
-			continue
+		if err == io.EOF {
+			return names, nil
 		}
-		// Split the file name into a slice
-		parts := strings.Split(header.Name, "/")
-		if len(parts) > 1 {
-			// Remove the first element
-			parts = parts[1:]
+		if err != nil {
+			return nil, err
 		}
-		// Join the remaining elements to get the new file name
-		fname := strings.Join(parts, "/")
-
-		target := filepath.Join(dest, fname)
-		switch header.Typeflag {
-		case tar.TypeDir:
-			if _, err := os.Stat(target); err != nil {
-				if err := os.MkdirAll(target, 0755); err != nil {
-					return err
-				}
-			}
-		case tar.TypeReg:
-			_ = os.MkdirAll(filepath.Dir(target), 0755)
-			f, err := os.OpenFile(target, os.O_CREATE|os.O_RDWR, os.FileMode(header.Mode))
-			if err != nil {
-				return err
-			}
+		names = append(names, header.Name)
+	}
+}
 
-			if _, err := io.Copy(f, tr); err != nil {
-				return err
-			}
+func openGzipTar(src string) (io.Reader, io.Closer, error) {
+	file, err := os.Open(src)
+	if err != nil {
+		return nil, nil, err
+	}
+	gzr, err := gzip.NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+	return gzr, file, nil
+}
 
-			f.Close()
-		case tar.TypeSymlink:
-			symlinks = append(symlinks, symlink{header.Linkname, target})
-		}
+func openXZTar(src string) (io.Reader, io.Closer, error) {
+	file, err := os.Open(src)
+	if err != nil {
+		return nil, nil, err
 	}
-	for _, s := range symlinks {
-		if err = os.Symlink(s.oldname, s.newname); err != nil {
-			return err
-		}
+	xzr, err := xz.NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, nil, err
 	}
-	return nil
+	return xzr, file, nil
+}
+
+func openPlainTar(src string) (io.Reader, io.Closer, error) {
+	file, err := os.Open(src)
+	if err != nil {
+		return nil, nil, err
+	}
+	return file, file, nil
+}
+
+type GzipTarDecompressor struct {
+	src   string
+	strip int
+}
+
+func (g *GzipTarDecompressor) Decompress(dest string) error {
+	return decompressTar(g.src, dest, g.strip, openGzipTar)
+}
+
+func (g *GzipTarDecompressor) List() ([]string, error) {
+	return listTar(g.src, openGzipTar)
+}
+
+type XZTarDecompressor struct {
+	src   string
+	strip int
+}
+
+func (g *XZTarDecompressor) Decompress(dest string) error {
+	return decompressTar(g.src, dest, g.strip, openXZTar)
+}
+
+func (g *XZTarDecompressor) List() ([]string, error) {
+	return listTar(g.src, openXZTar)
+}
+
+type TarDecompressor struct {
+	src   string
+	strip int
+}
+
+func (g *TarDecompressor) Decompress(dest string) error {
+	return decompressTar(g.src, dest, g.strip, openPlainTar)
+}
+
+func (g *TarDecompressor) List() ([]string, error) {
+	return listTar(g.src, openPlainTar)
 }
 
 type ZipDecompressor struct {
-	src string
+	src   string
+	strip int
 }
 
 func (z *ZipDecompressor) Decompress(dest string) error {
-	rootFolderInZip := findRootFolderInZip(z.src)
+	strip := z.strip
+	if strip == DefaultStrip {
+		// The top-level directory is removed only if all the files are in it.
+		if findRootFolderInZip(z.src) == "" {
+			strip = 0
+		}
+	}
 	r, err := zip.OpenReader(z.src)
 	if err != nil {
 		return err
 	}
 	defer r.Close()
 	for _, f := range r.File {
-		err := z.processZipFile(f, dest, rootFolderInZip)
+		err := z.processZipFile(f, dest, strip)
 		if err != nil {
 			return err
 		}
@@ -195,6 +261,19 @@ func (z *ZipDecompressor) Decompress(dest string) error {
 	return nil
 }
 
+func (z *ZipDecompressor) List() ([]string, error) {
+	r, err := zip.OpenReader(z.src)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	var names []string
+	for _, f := range r.File {
+		names = append(names, f.Name)
+	}
+	return names, nil
+}
+
 func findRootFolderInZip(zipFilePath string) string {
 	r, err := zip.OpenReader(zipFilePath)
 	if err != nil {
@@ -219,69 +298,79 @@ func findRootFolderInZip(zipFilePath string) string {
 	return firstElement
 }
 
-func (z *ZipDecompressor) processZipFile(f *zip.File, dest string, rootFolderInZip string) error {
+func (z *ZipDecompressor) processZipFile(f *zip.File, dest string, strip int) error {
+	fname, ok := stripComponents(f.Name, f.FileInfo().IsDir(), strip)
+	if !ok {
+		return nil
+	}
+	fpath, err := safeJoin(dest, fname)
+	if err != nil {
+		return err
+	}
+	if f.FileInfo().IsDir() {
+		return os.MkdirAll(fpath, os.ModePerm)
+	}
 	rc, err := f.Open()
 	if err != nil {
 		return err
 	}
 	defer rc.Close()
 
-	// Split the file name into a slice
-	parts := strings.Split(f.Name, "/")
-	if len(parts) > 1 && rootFolderInZip != "" {
-		// Remove the first element
-		parts = parts[1:]
+	if err = os.MkdirAll(filepath.Dir(fpath), os.ModePerm); err != nil {
+		return err
 	}
-	// Join the remaining elements to get the new file name
-	fname := strings.Join(parts, "/")
-
-	fpath := filepath.Join(dest, fname)
-	if f.FileInfo().IsDir() {
-		err := os.MkdirAll(fpath, os.ModePerm)
-		if err != nil {
-			return err
-		}
-	} else {
-		var fdir string
-		if lastIndex := strings.LastIndex(fpath, string(os.PathSeparator)); lastIndex > -1 {
-			fdir = fpath[:lastIndex]
-		}
+	out, err := os.OpenFile(fpath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, f.Mode())
+	if err != nil {
+		return err
+	}
+	defer out.Close()
 
-		err = os.MkdirAll(fdir, os.ModePerm)
-		if err != nil {
-			return err
-		}
-		f, err := os.OpenFile(
-			fpath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, f.Mode())
-		if err != nil {
-			return err
-		}
-		defer f.Close()
+	_, err = io.Copy(out, rc)
+	return err
+}
 
-		_, err = io.Copy(f, rc)
-		if err != nil {
-			return err
+// detectFormat returns the format of the archive from the file extension, or empty if it is not an archive.
+// A plain tar is never detected, it is left to the plugin unless the format is specified.
+func detectFormat(src string) string {
+	filename := strings.ToLower(filepath.Base(src))
+	for _, format := range []string{"tar.gz", "tgz", "tar.xz", "zip"} {
+		if strings.HasSuffix(filename, "."+format) {
+			return format
 		}
 	}
-	return nil
+	return ""
 }
 
 func NewDecompressor(src string) Decompressor {
-	filename := filepath.Base(src)
-	if strings.HasSuffix(filename, ".tar.gz") || strings.HasSuffix(filename, ".tgz") {
-		return &GzipTarDecompressor{
-			src: src,
-		}
+	d, _ := NewDecompressorWithOptions(src, nil)
+	return d
+}
+
+// NewDecompressorWithOptions returns the decompressor of the archive with the options, nil options are the defaults.
+// A nil decompressor is returned if the format is not detected from the file extension.
+func NewDecompressorWithOptions(src string, opts *DecompressOptions) (Decompressor, error) {
+	if opts == nil {
+		opts = &DecompressOptions{Strip: DefaultStrip}
 	}
-	if strings.HasSuffix(filename, ".tar.xz") {
-		return &XZTarDecompressor{
-			src: src,
-		}
+	if opts.Strip < DefaultStrip {
+		return nil, fmt.Errorf("invalid strip: %d", opts.Strip)
 	}
-	if strings.HasSuffix(filename, ".zip") {
-		return &ZipDecompressor{
-			src: src,
-		}
+	format := strings.ToLower(opts.Format)
+	if format == "" {
+		format = detectFormat(src)
+	}
+	switch format {
+	case "tar.gz", "tgz":
+		return &GzipTarDecompressor{src: src, strip: opts.Strip}, nil
+	case "tar.xz":
+		return &XZTarDecompressor{src: src, strip: opts.Strip}, nil
+	case "tar":
+		return &TarDecompressor{src: src, strip: opts.Strip}, nil
+	case "zip":
+		return &ZipDecompressor{src: src, strip: opts.Strip}, nil
+	case "":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unsupported archive format: %s", opts.Format)
 	}
-	return nil
 }
@@ -17,6 +17,13 @@
 package util
 
 import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"testing"
 )
 
@@ -37,6 +44,157 @@ func TestNewDecompressor(t *testing.T) {
 	}
 }
 
+func writeTarGz(t *testing.T, path string, files map[string]string) {
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gw := gzip.NewWriter(f)
+	defer gw.Close()
+	tw := tar.NewWriter(gw)
+	defer tw.Close()
+	for name, content := range files {
+		header := &tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}
+		if strings.HasSuffix(name, "/") {
+			header.Mode, header.Typeflag = 0755, tar.TypeDir
+		}
+		if err = tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func writeZip(t *testing.T, path string, files map[string]string) {
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	defer zw.Close()
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDecompressOptions(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "sdk.tar.gz")
+	writeTarGz(t, src, map[string]string{
+		"sdk-1.0.0/bin/sdk": "bin",
+		"sdk-1.0.0/LICENSE": "license",
+	})
+	tests := []struct {
+		opts *DecompressOptions
+		want []string
+	}{
+		{nil, []string{"LICENSE", "bin/sdk"}},
+		{&DecompressOptions{Strip: 0}, []string{"sdk-1.0.0/LICENSE", "sdk-1.0.0/bin/sdk"}},
+		{&DecompressOptions{Strip: 2}, []string{"sdk"}},
+	}
+	for i, tt := range tests {
+		dest := filepath.Join(dir, "dest", string(rune('a'+i)))
+		d, err := NewDecompressorWithOptions(src, tt.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = d.Decompress(dest); err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		_ = filepath.Walk(dest, func(path string, info os.FileInfo, err error) error {
+			if err == nil && !info.IsDir() {
+				rel, _ := filepath.Rel(dest, path)
+				got = append(got, filepath.ToSlash(rel))
+			}
+			return nil
+		})
+		sort.Strings(got)
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("Decompress with %+v got %v, want %v", tt.opts, got, tt.want)
+		}
+	}
+
+	d, _ := NewDecompressorWithOptions(src, nil)
+	names, err := d.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 2 {
+		t.Errorf("Expected 2 entries, got %v", names)
+	}
+
+	// The format is specified for a file without a known extension.
+	renamed := filepath.Join(dir, "sdk.bin")
+	if err = os.Rename(src, renamed); err != nil {
+		t.Fatal(err)
+	}
+	if d, _ = NewDecompressorWithOptions(renamed, nil); d != nil {
+		t.Errorf("Expected nil, got %T", d)
+	}
+	if d, _ = NewDecompressorWithOptions(renamed, &DecompressOptions{Format: "tgz", Strip: DefaultStrip}); d == nil {
+		t.Error("Expected a decompressor for the specified format")
+	}
+	if _, err = NewDecompressorWithOptions(renamed, &DecompressOptions{Format: "rar"}); err == nil {
+		t.Error("Expected an error for an unsupported format")
+	}
+}
+
+func TestDecompressDirectoryEntries(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"sdk-1.0.0/":        "",
+		"sdk-1.0.0/bin/":    "",
+		"sdk-1.0.0/bin/sdk": "bin",
+	}
+	for _, src := range []string{filepath.Join(dir, "sdk.tar.gz"), filepath.Join(dir, "sdk.zip")} {
+		if strings.HasSuffix(src, ".zip") {
+			writeZip(t, src, files)
+		} else {
+			writeTarGz(t, src, files)
+		}
+		dest := filepath.Join(dir, filepath.Base(src)+".dest")
+		if err := NewDecompressor(src).Decompress(dest); err != nil {
+			t.Fatal(err)
+		}
+		if FileExists(filepath.Join(dest, "sdk-1.0.0")) {
+			t.Errorf("Expected the top-level directory of %s to map to dest", src)
+		}
+		if !FileExists(filepath.Join(dest, "bin", "sdk")) {
+			t.Errorf("Expected bin/sdk of %s to be decompressed", src)
+		}
+	}
+}
+
+func TestDecompressZip(t *testing.T) {
+	dir := t.TempDir()
+	// A single file is not a top-level directory.
+	src := filepath.Join(dir, "single.zip")
+	writeZip(t, src, map[string]string{"sdk.exe": "exe"})
+	if err := NewDecompressor(src).Decompress(filepath.Join(dir, "single")); err != nil {
+		t.Fatal(err)
+	}
+	if !FileExists(filepath.Join(dir, "single", "sdk.exe")) {
+		t.Error("Expected sdk.exe to be decompressed")
+	}
+
+	src = filepath.Join(dir, "evil.zip")
+	writeZip(t, src, map[string]string{"a/../../evil": "evil"})
+	if err := NewDecompressor(src).Decompress(filepath.Join(dir, "evil")); err == nil {
+		t.Error("Expected an error for the entry outside of dest")
+	}
+}
+
 //func TestDecompress(t *testing.T) {
 //	// Create a temporary directory for testing
 //	tempDir, err := os.MkdirTemp("", "decompress_test")
@@ -17,6 +17,9 @@
 package util
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -36,3 +39,22 @@ func IsBeforeToday(timestamp int64) bool {
 	now = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
 	return t.Before(now)
 }
+
+// ParseDuration parses a duration like time.ParseDuration, and additionally accepts
+// the units `d` (day) and `w` (week), e.g. 30d, 2w.
+func ParseDuration(s string) (time.Duration, error) {
+	units := map[string]time.Duration{
+		"d": 24 * time.Hour,
+		"w": 7 * 24 * time.Hour,
+	}
+	for unit, d := range units {
+		if n, ok := strings.CutSuffix(s, unit); ok {
+			i, err := strconv.Atoi(n)
+			if err != nil {
+				return 0, fmt.Errorf("invalid duration %q", s)
+			}
+			return time.Duration(i) * d, nil
+		}
+	}
+	return time.ParseDuration(s)
+}
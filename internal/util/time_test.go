@@ -39,3 +39,23 @@ func TestIsBeforeToday(t *testing.T) {
 		t.Errorf("IsBeforeToday(tomorrow) = true, want false")
 	}
 }
+
+func TestParseDuration(t *testing.T) {
+	tests := map[string]time.Duration{
+		"30d": 30 * 24 * time.Hour,
+		"2w":  14 * 24 * time.Hour,
+		"12h": 12 * time.Hour,
+	}
+	for s, want := range tests {
+		got, err := ParseDuration(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("ParseDuration(%s) = %v, want %v", s, got, want)
+		}
+	}
+	if _, err := ParseDuration("xd"); err == nil {
+		t.Errorf("expected error for invalid duration")
+	}
+}
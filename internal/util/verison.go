@@ -17,45 +17,164 @@
 package util
 
 import (
+	"regexp"
 	"strconv"
 	"strings"
 )
 
-func CompareVersion(v1, v2 string) int {
-	parts1 := strings.Split(v1, ".")
-	parts2 := strings.Split(v2, ".")
+// unstableMarkers are the pre-release identifiers that make a version unstable.
+// Other suffixes, such as `17.0.2-tem`, are treated as vendor qualifiers.
+var unstableMarkers = []string{"alpha", "beta", "rc", "pre", "preview", "dev", "snapshot", "ea", "nightly", "canary", "next"}
+
+var exactVersionRegex = regexp.MustCompile(`^[vV]?\d+\.\d+\.\d+([-+_][0-9A-Za-z.\-+_]*)?$`)
+
+type versionParts struct {
+	segments   []string
+	prerelease string
+}
 
-	len1 := len(parts1)
-	len2 := len(parts2)
+// parseVersionParts splits a version into its dot-separated segments and the pre-release part.
+// The leading `v` and the build metadata are dropped.
+func parseVersionParts(v string) versionParts {
+	v = strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(v), "v"), "V")
+	if i := strings.Index(v, "+"); i >= 0 {
+		v = v[:i]
+	}
+	var pre string
+	if i := strings.Index(v, "-"); i >= 0 {
+		v, pre = v[:i], v[i+1:]
+	}
+	return versionParts{
+		segments:   strings.Split(v, "."),
+		prerelease: pre,
+	}
+}
 
-	// Get the maximum length between two versions
-	maxLen := len1
-	if len2 > maxLen {
-		maxLen = len2
+// splitSegment splits a segment into the leading number and the rest, e.g. `0_292` -> 0, `_292`.
+func splitSegment(s string) (int, string, bool) {
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	if i == 0 {
+		return 0, s, false
 	}
+	n, err := strconv.Atoi(s[:i])
+	if err != nil {
+		return 0, s, false
+	}
+	return n, s[i:], true
+}
 
+// compareSegment compares two segments in natural order, e.g. `0_72` < `0_292`.
+func compareSegment(s1, s2 string) int {
+	for s1 != "" || s2 != "" {
+		n1, rest1, ok1 := splitSegment(s1)
+		n2, rest2, ok2 := splitSegment(s2)
+		switch {
+		case ok1 && ok2:
+			if n1 != n2 {
+				if n1 > n2 {
+					return 1
+				}
+				return -1
+			}
+			s1, s2 = rest1, rest2
+			continue
+		case ok1 != ok2:
+			// numeric parts are always greater than non-numeric ones
+			if ok1 {
+				return 1
+			}
+			return -1
+		}
+		// compare the non-numeric prefixes
+		p1, p2 := nonNumericPrefix(s1), nonNumericPrefix(s2)
+		if c := strings.Compare(p1, p2); c != 0 {
+			return c
+		}
+		s1, s2 = s1[len(p1):], s2[len(p2):]
+	}
+	return 0
+}
+
+func nonNumericPrefix(s string) string {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= '0' && s[i] <= '9' {
+			return s[:i]
+		}
+	}
+	return s
+}
+
+func compareSegments(parts1, parts2 []string) int {
+	maxLen := len(parts1)
+	if len(parts2) > maxLen {
+		maxLen = len(parts2)
+	}
 	for i := 0; i < maxLen; i++ {
 		// Because the length of v1 or v2 may be less than maxLen
 		// We assume the missing part as 0
-		part1 := 0
-		if i < len1 {
-			part1, _ = strconv.Atoi(parts1[i])
+		part1, part2 := "0", "0"
+		if i < len(parts1) {
+			part1 = parts1[i]
 		}
+		if i < len(parts2) {
+			part2 = parts2[i]
+		}
+		if c := compareSegment(part1, part2); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
 
-		part2 := 0
-		if i < len2 {
-			part2, _ = strconv.Atoi(parts2[i])
+// CompareVersion compares two versions, returns 1 if v1 > v2, -1 if v1 < v2, otherwise 0.
+// An unstable version (see IsPrerelease) is lower than the same version without it,
+// e.g. 1.0.0-rc.1 < 1.0.0, while a vendor qualifier is higher, e.g. 17.0.2 < 17.0.2-tem.
+func CompareVersion(v1, v2 string) int {
+	p1 := parseVersionParts(v1)
+	p2 := parseVersionParts(v2)
+
+	if c := compareSegments(p1.segments, p2.segments); c != 0 {
+		return c
+	}
+	if pre1, pre2 := IsPrerelease(v1), IsPrerelease(v2); pre1 != pre2 {
+		if pre1 {
+			return -1
 		}
+		return 1
+	}
+	return compareSegments(strings.Split(p1.prerelease, "."), strings.Split(p2.prerelease, "."))
+}
 
-		if part1 != part2 {
-			if part1 > part2 {
-				return 1
-			} else {
-				return -1
-			}
+// IsPrerelease reports whether the version is an unstable version, such as 1.0.0-rc.1 or 21-ea.
+func IsPrerelease(v string) bool {
+	pre := strings.ToLower(parseVersionParts(v).prerelease)
+	if pre == "" {
+		return false
+	}
+	for _, marker := range unstableMarkers {
+		if strings.HasPrefix(pre, marker) {
+			return true
 		}
 	}
+	return false
+}
 
-	// If all parts are equal
-	return 0
+// IsExactVersion reports whether the version is a complete version number,
+// which does not need to be resolved against a version list, such as 1.2.3 or 17.0.2+8.
+func IsExactVersion(v string) bool {
+	return exactVersionRegex.MatchString(strings.TrimSpace(v))
+}
+
+// HasSamePrefix reports whether the first n segments of the versions are equal,
+// e.g. 20.1.0 and 20.1.5 have the same prefix of 2 segments.
+func HasSamePrefix(v1, v2 string, n int) bool {
+	s1 := parseVersionParts(v1).segments
+	s2 := parseVersionParts(v2).segments
+	if len(s1) < n || len(s2) < n {
+		return false
+	}
+	return compareSegments(s1[:n], s2[:n]) == 0
 }
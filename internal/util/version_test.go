@@ -76,6 +76,54 @@ func TestCompareVersion(t *testing.T) {
 			},
 			want: 0,
 		},
+		{
+			name: "pre-release < release",
+			args: args{
+				v1: "1.0.0-rc.1",
+				v2: "1.0.0",
+			},
+			want: -1,
+		},
+		{
+			name: "pre-release order",
+			args: args{
+				v1: "1.0.0-rc.10",
+				v2: "1.0.0-rc.2",
+			},
+			want: 1,
+		},
+		{
+			name: "vendor qualifier > release",
+			args: args{
+				v1: "17.0.2-tem",
+				v2: "17.0.2",
+			},
+			want: 1,
+		},
+		{
+			name: "vendor qualifier > pre-release",
+			args: args{
+				v1: "21.0.1-ea",
+				v2: "21.0.1-zulu",
+			},
+			want: -1,
+		},
+		{
+			name: "non-numeric segment",
+			args: args{
+				v1: "1.8.0_292",
+				v2: "1.8.0_72",
+			},
+			want: 1,
+		},
+		{
+			name: "v prefix",
+			args: args{
+				v1: "v20.1.0",
+				v2: "20.1",
+			},
+			want: 0,
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
@@ -85,3 +133,23 @@ func TestCompareVersion(t *testing.T) {
 		})
 	}
 }
+
+func TestHasSamePrefix(t *testing.T) {
+	tests := []struct {
+		v1, v2 string
+		n      int
+		want   bool
+	}{
+		{"20.1.0", "20.1.5", 2, true},
+		{"20.1.0", "20.2.0", 2, false},
+		{"20.1.0", "v20.2.0", 1, true},
+		{"20.1.0", "21.1.0", 1, false},
+		{"20", "20.1.0", 2, false},
+		{"17.0.2-tem", "17.0.9", 2, true},
+	}
+	for _, tt := range tests {
+		if got := HasSamePrefix(tt.v1, tt.v2, tt.n); got != tt.want {
+			t.Errorf("HasSamePrefix(%s, %s, %d) = %v, want %v", tt.v1, tt.v2, tt.n, got, tt.want)
+		}
+	}
+}
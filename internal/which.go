@@ -0,0 +1,61 @@
+/*
+ *    Copyright 2024 Han Li and contributors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package internal
+
+import "fmt"
+
+// Which returns the binary in the PATH entries of the active sdk versions, and the sdk
+// and version providing it. The sdks are searched in the order of their names.
+func (m *Manager) Which(name string) (string, *Sdk, Version, error) {
+	for _, sdkName := range m.recordedSdks() {
+		sdk, err := m.LookupSdk(sdkName)
+		if err != nil {
+			continue
+		}
+		version := sdk.Current()
+		envs, err := sdk.EnvKeys(version)
+		if err != nil {
+			continue
+		}
+		if bin := m.lookupBinary(envs.Paths, name); bin != "" {
+			return bin, sdk, version, nil
+		}
+	}
+	return "", nil, "", fmt.Errorf("%s is not found in the active sdk versions", name)
+}
+
+// Where returns the install path of the main package of the version. The current version
+// is used if the version is empty, and a version constraint is resolved against the installed versions.
+func (b *Sdk) Where(version Version) (string, error) {
+	if version == "" {
+		version = b.Current()
+		if version == "" {
+			return "", fmt.Errorf("no current version of %s", b.Plugin.SdkName)
+		}
+	}
+	if !b.checkExists(version) {
+		version = b.resolveLocalVersion(version)
+	}
+	if !b.checkExists(version) {
+		return "", fmt.Errorf("%s is not installed", b.label(version))
+	}
+	pkg, err := b.getLocalSdkPackage(version)
+	if err != nil {
+		return b.VersionPath(version), nil
+	}
+	return pkg.Main.Path, nil
+}
@@ -22,6 +22,11 @@ PLUGIN = {
     updateUrl = "{URL}/sdk.lua",
     -- minimum compatible vfox version
     minRuntimeVersion = "0.2.2",
+    -- version files of other tools, which are read by ParseLegacyFile [optional]
+    legacyFilenames = {
+        ".nvmrc",
+        ".node-version",
+    },
 }
 
 --- Returns some pre-installed information, such as version number, download address, local files, etc.
@@ -45,6 +50,14 @@ function PLUGIN:PreInstall(ctx)
         sha1 = "xxx",
         --- sha512 checksum [optional]
         sha512 = "xx",
+        --- detached signature of the file [optional]
+        signature_url = "xxx",
+        --- gpg/minisign/cosign [optional]
+        signature_type = "gpg",
+        --- the name of a key file or directory under ~/.version-fox/keys [optional]
+        public_key = "xxx",
+        --- how to unpack the archive, also available for each addition [optional]
+        decompress = { format = "tar.gz", strip = 2 },
         --- additional need files [optional]
         addition = {
             {
@@ -79,14 +92,18 @@ function PLUGIN:PostInstall(ctx)
 end
 
 --- Return all available versions provided by this plugin
---- @param ctx table Empty table used as context, for future extension
+--- @param ctx table Context information
+--- @field ctx.args table Extra arguments of `vfox search`, such as { "temurin" } for `vfox search java temurin`
 --- @return table Descriptions of available versions and accompanying tool descriptions
 function PLUGIN:Available(ctx)
     local runtimeVersion = ctx.runtimeVersion
+    local args = ctx.args
     return {
         {
             version = "xxxx",
             note = "LTS",
+            --- [optional] aliases of the version, such as `vfox install nodejs@lts`
+            aliases = { "lts" },
             addition = {
                 {
                     name = "npm",
@@ -160,3 +177,16 @@ function PLUGIN:PreUse(ctx)
         version = version,
     }
 end
+
+--- Reads the version from a legacy version file declared in `legacyFilenames`, such as .nvmrc.
+--- If this function is not implemented, the first line of the file is used.
+--- @param ctx table Context information
+function PLUGIN:ParseLegacyFile(ctx)
+    --- the path of the file
+    local filepath = ctx.filepath
+    --- the name of the file, such as .nvmrc
+    local filename = ctx.filename
+    return {
+        version = "xxx",
+    }
+end